@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// An agarDefect is the outcome of flipping a single cell of a tiled agar
+type agarDefect struct {
+	flip   engine.Coord // relative to the tile the defect is in
+	healed int          // generation the difference vanished, 0 if it never did
+	final  int          // cells differing from the perfect agar after the last generation
+	spread int          // distance of the farthest differing cell from the defect
+}
+
+// outcome classifies the defect: it healed, it grew beyond threshold
+// differing cells, it propagated farther than reach cells away from where
+// it started, or it persists where it is
+func (d agarDefect) outcome(threshold, reach int) string {
+	switch {
+	case d.healed > 0:
+		return "heals"
+	case d.final > threshold:
+		return "grows"
+	case d.spread > reach:
+		return "propagates"
+	}
+	return "persists"
+}
+
+// agarRun runs the agar with the cell at defect flipped next to the
+// generations of the perfect agar, on the torus of the engine
+func agarRun(e engine.Engine, baseline []engine.World, defect engine.Coord) agarDefect {
+	var d agarDefect
+
+	world := make(engine.World, len(baseline[0]))
+	for coord, cell := range baseline[0] {
+		world[coord] = cell
+	}
+	if world[defect].Alive {
+		delete(world, defect)
+	} else {
+		world[defect] = engine.Cell{Alive: true}
+	}
+
+	for gen := 1; gen < len(baseline); gen++ {
+		world = e.Tick(world)
+		if engine.Hamming(world, baseline[gen]) == 0 {
+			d.healed = gen
+			return d
+		}
+	}
+
+	last := baseline[len(baseline)-1]
+	for _, w := range []engine.World{world, last} {
+		for coord, cell := range w {
+			if !cell.Alive || world[coord].Alive == last[coord].Alive {
+				continue
+			}
+			d.final++
+			v := e.Torus.Wrap(engine.Coord{X: coord.X - defect.X, Y: coord.Y - defect.Y})
+			d.spread = max(d.spread, v.X, -v.X, v.Y, -v.Y)
+		}
+	}
+	return d
+}
+
+// runAgar implements the agar subcommand: it tiles a pattern over a torus
+// and reports for single cell defects whether the agar heals them, or
+// whether they grow, propagate or persist
+func runAgar(args []string) error {
+	fs := flag.NewFlagSet("agar", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: gol agar [flags]\n\nTests how a tiled agar copes with single cell defects.\n\n")
+		fs.PrintDefaults()
+	}
+	loadPattern := patternFlags(fs)
+	tileWidth := fs.Int("tile-width", 0, "width of a tile of the agar in `cells`, 0 for the width of the pattern")
+	tileHeight := fs.Int("tile-height", 0, "height of a tile of the agar in `cells`, 0 for the height of the pattern")
+	tiles := fs.Int("tiles", 8, "tile the torus with `n` x n tiles")
+	ticks := fs.Int("ticks", 200, "number of generations to run every defect")
+	defectOpt := fs.String("defect", "", "flip only the cell at `x,y` of the tile, instead of every cell of it in turn")
+	threshold := fs.Int("threshold", 50, "a defect grows once more than `n` cells differ from the perfect agar")
+	ruleOpt := fs.String("rule", "", "life-like `rule` in B/S notation, defaults to the rule of the pattern file, or B3/S23")
+	fs.Parse(args)
+
+	p, err := loadPattern()
+	if err != nil {
+		return err
+	}
+	p = p.Normalize()
+	if len(p.Cells) == 0 {
+		return fmt.Errorf("the agar has no live cells")
+	}
+	_, hi := p.Bounds()
+	w, h := *tileWidth, *tileHeight
+	if w == 0 {
+		w = hi.X + 1
+	}
+	if h == 0 {
+		h = hi.Y + 1
+	}
+	if w < 1 || h < 1 || *tiles < 1 {
+		return fmt.Errorf("invalid tiling of %d x %d tiles of %dx%d cells", *tiles, *tiles, w, h)
+	}
+
+	rule := engine.Conway
+	if *ruleOpt == "" {
+		*ruleOpt = p.Rule
+	}
+	if *ruleOpt != "" {
+		if rule, err = engine.ParseRule(*ruleOpt); err != nil {
+			return err
+		}
+	}
+	if rule.Birth[0] {
+		return fmt.Errorf("the rule %s with B0 is not supported, it needs -engine dense", rule)
+	}
+
+	torus := engine.Torus{Width: *tiles * w, Height: *tiles * h}
+	e := engine.Engine{Rule: rule, Torus: &torus, Workers: 1}
+
+	// The tile the defects go into is the one at the origin
+	world := make(engine.World)
+	p.Tile(world, engine.Coord{X: -(*tiles / 2) * w, Y: -(*tiles / 2) * h}, w, h, *tiles, *tiles)
+	baseline := []engine.World{torus.Fold(world)}
+	for gen := 1; gen <= *ticks; gen++ {
+		baseline = append(baseline, e.Tick(baseline[gen-1]))
+	}
+
+	var flips []engine.Coord
+	if *defectOpt != "" {
+		defect, err := parseCoordPair(*defectOpt)
+		if err != nil {
+			return err
+		}
+		flips = append(flips, defect)
+	} else {
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				flips = append(flips, engine.Coord{X: x, Y: y})
+			}
+		}
+	}
+
+	results := make([]agarDefect, len(flips))
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for i := 0; i < cntWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = agarRun(e, baseline, torus.Wrap(flips[i]))
+				results[i].flip = flips[i]
+			}
+		}()
+	}
+	for i := range flips {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	fmt.Printf("# %s agar of %d x %d tiles of %dx%d cells, rule %s, ticks=%d\n", p.Name, *tiles, *tiles, w, h, rule, *ticks)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "defect\toutcome\tgeneration\tfinal difference\tspread")
+	for _, r := range results {
+		gen := ""
+		if r.healed > 0 {
+			gen = fmt.Sprint(r.healed)
+		}
+		fmt.Fprintf(tw, "%d,%d\t%s\t%s\t%d\t%d\n", r.flip.X, r.flip.Y, r.outcome(*threshold, max(w, h)), gen, r.final, r.spread)
+	}
+	return tw.Flush()
+}
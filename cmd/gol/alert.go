@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// An alert watches a quantity of the world during a run and acts once it
+// meets a threshold, e.g. pop>100000:stop. It fires at most once.
+type alert struct {
+	spec   string
+	metric string // pop, width, height or gen
+	op     string
+	value  int
+	action string // stop, dump, exec or notify
+	arg    string // file for dump, shell command for exec
+	fired  bool
+}
+
+var alertMetrics = []string{"pop", "width", "height", "gen"}
+
+// alertOps are the comparisons of an alert, longest first so that >= is
+// not taken for >
+var alertOps = []string{">=", "<=", ">", "<", "="}
+
+// parseAlert parses an alert written as metric op value:action, where the
+// action is stop, dump[=file], exec=command or notify to post to the
+// -webhook
+func parseAlert(s string) (alert, error) {
+	a := alert{spec: s}
+	cond, action, found := strings.Cut(s, ":")
+	if !found {
+		return a, fmt.Errorf("invalid alert %q, expected condition:action", s)
+	}
+
+	for _, op := range alertOps {
+		if metric, value, found := strings.Cut(cond, op); found {
+			a.metric, a.op = strings.TrimSpace(metric), op
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return a, fmt.Errorf("invalid threshold in alert %q", s)
+			}
+			a.value = n
+			break
+		}
+	}
+	if a.op == "" {
+		return a, fmt.Errorf("invalid condition in alert %q", s)
+	}
+	if !slices.Contains(alertMetrics, a.metric) {
+		return a, fmt.Errorf("unknown quantity %q in alert %q, expected one of %s", a.metric, s, strings.Join(alertMetrics, ", "))
+	}
+
+	a.action, a.arg, _ = strings.Cut(action, "=")
+	switch a.action {
+	case "stop":
+	case "dump":
+		if a.arg == "" {
+			a.arg = "gol-%d.cells"
+		}
+	case "exec":
+		if a.arg == "" {
+			return a, fmt.Errorf("alert %q lacks the command to execute", s)
+		}
+	case "notify":
+	default:
+		return a, fmt.Errorf("unknown action %q in alert %q, expected stop, dump, exec or notify", a.action, s)
+	}
+	return a, nil
+}
+
+// alertList collects the -alert flags
+type alertList []alert
+
+func (l *alertList) String() string {
+	specs := make([]string, len(*l))
+	for i, a := range *l {
+		specs[i] = a.spec
+	}
+	return strings.Join(specs, ", ")
+}
+
+func (l *alertList) Set(s string) error {
+	a, err := parseAlert(s)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, a)
+	return nil
+}
+
+// worldStats are the quantities of a generation alerts can watch
+type worldStats struct {
+	gen, pop      int
+	width, height int
+	min, max      engine.Coord // bounding box of the live cells
+}
+
+func statsOf(world engine.World, gen int) worldStats {
+	s := worldStats{gen: gen}
+	var lo, hi engine.Coord
+	for c, cell := range world {
+		if !cell.Alive {
+			continue
+		}
+		if s.pop == 0 {
+			lo, hi = c, c
+		}
+		lo.X, lo.Y = min(lo.X, c.X), min(lo.Y, c.Y)
+		hi.X, hi.Y = max(hi.X, c.X), max(hi.Y, c.Y)
+		s.pop++
+	}
+	if s.pop > 0 {
+		s.width, s.height = hi.X-lo.X+1, hi.Y-lo.Y+1
+		s.min, s.max = lo, hi
+	}
+	return s
+}
+
+func (a *alert) met(s worldStats) bool {
+	v := map[string]int{"pop": s.pop, "width": s.width, "height": s.height, "gen": s.gen}[a.metric]
+	switch a.op {
+	case ">=":
+		return v >= a.value
+	case "<=":
+		return v <= a.value
+	case ">":
+		return v > a.value
+	case "<":
+		return v < a.value
+	}
+	return v == a.value
+}
+
+// checkAlerts fires the alerts of the run met by the generation and tells
+// if one of them stops the run. Every alert that fires is reported on
+// stderr.
+func checkAlerts(cfg config, world engine.World, gen int) (stop bool, err error) {
+	s := statsOf(world, gen)
+	for i := range cfg.alerts {
+		a := &cfg.alerts[i]
+		if a.fired || !a.met(s) {
+			continue
+		}
+		a.fired = true
+		fmt.Fprintf(os.Stderr, "alert %s at generation %d, population %d\n", a.spec, gen, s.pop)
+
+		switch a.action {
+		case "stop":
+			stop = true
+		case "dump":
+			path := a.arg
+			if strings.Contains(path, "%") {
+				path = fmt.Sprintf(path, gen)
+			}
+			f, err := os.Create(path)
+			if err != nil {
+				return stop, err
+			}
+			if err := writeCellsSnapshot(f, path, world, gen); err != nil {
+				f.Close()
+				return stop, err
+			}
+			if err := f.Close(); err != nil {
+				return stop, err
+			}
+		case "exec":
+			// The hook learns about the generation from its environment
+			cmd := exec.Command("sh", "-c", a.arg)
+			cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+			cmd.Env = append(os.Environ(),
+				"GOL_ALERT="+a.spec,
+				"GOL_GEN="+strconv.Itoa(gen),
+				"GOL_POP="+strconv.Itoa(s.pop),
+				fmt.Sprintf("GOL_BOUNDS=%dx%d", s.width, s.height))
+			if err := cmd.Run(); err != nil {
+				return stop, fmt.Errorf("alert %s: %v", a.spec, err)
+			}
+		case "notify":
+			e := newWebhookEvent(cfg, "alert", s)
+			e.Alert = a.spec
+			if err := postWebhook(cfg.webhook, e); err != nil {
+				return stop, err
+			}
+		}
+	}
+	return stop, nil
+}
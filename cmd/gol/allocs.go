@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// allocCounter adds up the heap allocations of the generations of a run,
+// for -debug-allocs
+type allocCounter struct {
+	mallocs, bytes uint64
+	gens           int
+	before         runtime.MemStats
+}
+
+// start begins counting the allocations of the next gens generations
+func (c *allocCounter) start(gens int) {
+	runtime.ReadMemStats(&c.before)
+	c.gens += gens
+}
+
+// stop ends counting what start began
+func (c *allocCounter) stop() {
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	c.mallocs += after.Mallocs - c.before.Mallocs
+	c.bytes += after.TotalAlloc - c.before.TotalAlloc
+}
+
+func (c *allocCounter) String() string {
+	if c.gens == 0 {
+		return "no generations"
+	}
+	g := uint64(c.gens)
+	return fmt.Sprintf("%d allocations and %d bytes in %d generations, %d allocations and %d bytes per generation",
+		c.mallocs, c.bytes, c.gens, c.mallocs/g, c.bytes/g)
+}
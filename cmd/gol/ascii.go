@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// asciiFrame draws the d x d view around the origin as text, with O for a
+// live and . for a dead cell. The top line is the largest y, so the frame
+// shows the world the way gnuplot plots it.
+func asciiFrame(world engine.World, d int) []string {
+	h := d / 2
+	lines := make([]string, 0, 2*h+1)
+	var b strings.Builder
+	for y := h; y >= -h; y-- {
+		b.Reset()
+		for x := -h; x <= h; x++ {
+			if world[engine.Coord{X: x, Y: y}].Alive {
+				b.WriteByte('O')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// asciiFrameInterval is the time between two frames of an asciinema
+// recording made without -max-gps; with it the frames are as far apart as
+// the governor keeps them
+const asciiFrameInterval = 100 * time.Millisecond
+
+// asciiExporter writes every generation as a text frame. If the file name
+// ends in .cast the frames are an asciicast v2 recording for asciinema,
+// otherwise they are written one after the other, each under a line with
+// its generation.
+type asciiExporter struct {
+	f        *os.File
+	w        *bufio.Writer
+	d        int
+	cast     bool
+	interval time.Duration
+	frames   int // written so far
+}
+
+func newASCIIExporter(path string, cfg config) (*asciiExporter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &asciiExporter{
+		f:        f,
+		w:        bufio.NewWriter(f),
+		d:        cfg.size,
+		cast:     strings.HasSuffix(path, ".cast"),
+		interval: asciiFrameInterval,
+	}
+	if cfg.maxGPS > 0 {
+		e.interval = governorInterval(cfg.step, cfg.maxGPS)
+	}
+
+	if e.cast {
+		side := 2*(cfg.size/2) + 1
+		header, _ := json.Marshal(map[string]interface{}{
+			"version": 2,
+			"width":   side,
+			"height":  side + 1,
+			"title":   cfg.pattern.Name,
+		})
+		e.w.Write(header)
+		e.w.WriteByte('\n')
+	}
+	return e, nil
+}
+
+func (e *asciiExporter) export(world engine.World, gen int) error {
+	lines := asciiFrame(world, e.d)
+	if !e.cast {
+		fmt.Fprintf(e.w, "generation %d\n%s\n\n", gen, strings.Join(lines, "\n"))
+		return nil
+	}
+
+	// Every frame clears the screen and redraws it from the top left corner
+	frame := fmt.Sprintf("\x1b[H\x1b[2Jgeneration %d\r\n%s", gen, strings.Join(lines, "\r\n"))
+	data, _ := json.Marshal(frame)
+	t := time.Duration(e.frames) * e.interval
+	e.frames++
+	fmt.Fprintf(e.w, "[%.6f, \"o\", %s]\n", t.Seconds(), data)
+	return nil
+}
+
+func (e *asciiExporter) close() error {
+	if err := e.w.Flush(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// A benchmark is the outcome of gol bench
+type benchmark struct {
+	elapsed time.Duration // computing the generations, without measuring the memory
+	allocs  allocCounter
+	peak    uint64 // largest live heap after a generation, in bytes
+	final   int    // population of the last generation
+}
+
+// runBenchmark computes ticks generations of the world without any output,
+// with the rule and topology of e and the named engine: map, incremental or
+// dense
+func runBenchmark(e engine.Engine, world engine.World, ticks int, name string) benchmark {
+	var b benchmark
+	var m runtime.MemStats
+	runtime.GC()
+	b.allocs.start(ticks)
+	tick := func() { world = e.Tick(world) }
+	population := func() int { return len(world.LiveCells()) }
+	switch name {
+	case "incremental":
+		in := engine.NewIncremental(e, world)
+		tick, population = in.Step, in.Population
+	case "dense":
+		d := engine.NewDense(e, world)
+		tick, population = d.Step, d.Population
+	}
+	for i := 0; i < ticks; i++ {
+		start := time.Now()
+		tick()
+		b.elapsed += time.Since(start)
+		runtime.ReadMemStats(&m)
+		b.peak = max(b.peak, m.HeapAlloc)
+	}
+	b.allocs.stop()
+	b.final = population()
+	return b
+}
+
+// runBench implements the bench subcommand: it runs a fixed workload
+// without output and reports how fast it went and how much memory it took
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: gol bench [flags]\n\nRuns a random soup without output and reports generations per second, allocations and peak memory.\n\n")
+		fs.PrintDefaults()
+	}
+	size := fs.Int("size", 256, "side of the random soup in `cells`")
+	ticks := fs.Int("ticks", 500, "number of generations to compute")
+	density := fs.Float64("density", 0.2, "probability of a cell of the soup being alive")
+	seed := fs.Int64("seed", 1, "seed for the soup, the same for every run unless changed")
+	workers := fs.Int("workers", cntWorkers, "number of goroutines computing a generation")
+	ruleOpt := fs.String("rule", "", "life-like `rule` in B/S notation or Generations rule in S/B/C notation, defaults to B3/S23")
+	engineOpt := fs.String("engine", "map", "engine computing the generations: map, incremental or dense")
+	torus := fs.Bool("torus", false, "run the soup on a torus as large as the soup instead of the plane, as -engine dense needs")
+	fs.Parse(args)
+
+	if *size < 1 || *ticks < 1 || *workers < 1 {
+		return fmt.Errorf("invalid benchmark of %d generations of a %dx%d soup with %d workers", *ticks, *size, *size, *workers)
+	}
+	switch *engineOpt {
+	case "map", "incremental":
+	case "dense":
+		if !*torus {
+			return fmt.Errorf("-engine dense needs -torus")
+		}
+	default:
+		return fmt.Errorf("unknown engine %q", *engineOpt)
+	}
+	if *density < 0 || *density > 1 {
+		return fmt.Errorf("invalid density %g, expected a probability from 0 to 1", *density)
+	}
+	e := engine.Engine{Rule: engine.Conway, Workers: *workers}
+	if *ruleOpt != "" {
+		var err error
+		if e.Rule, err = engine.ParseRule(*ruleOpt); err != nil {
+			return err
+		}
+	}
+	if e.Rule.States > 0 && *engineOpt != "map" {
+		return fmt.Errorf("the Generations rule %s can only be computed with -engine map", e.Rule)
+	}
+	if e.Rule.Birth[0] && *engineOpt != "dense" {
+		return fmt.Errorf("the rule %s with B0 can only be computed with -engine dense", e.Rule)
+	}
+
+	world := make(engine.World)
+	engine.FillSoupDensity(world, engine.NewMathRNG(*seed), *size, *workers, *density)
+	where := "the plane"
+	if *torus {
+		e.Torus = &engine.Torus{Width: *size, Height: *size}
+		world = e.Torus.Fold(world)
+		where = "a " + e.Torus.String()
+	}
+	fmt.Printf("# %d generations of a %dx%d soup of density %g on %s, seed %d, rule %s, engine %s, workers=%d\n", *ticks, *size, *size, *density, where, *seed, e.Rule, *engineOpt, *workers)
+	b := runBenchmark(e, world, *ticks, *engineOpt)
+
+	fmt.Printf("time:        %s, %.1f generations/s\n", b.elapsed.Round(time.Millisecond), float64(*ticks)/b.elapsed.Seconds())
+	fmt.Printf("allocations: %s\n", &b.allocs)
+	fmt.Printf("peak heap:   %.1f MiB\n", float64(b.peak)/(1<<20))
+	fmt.Printf("population:  %d at generation %d\n", b.final, *ticks)
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/miromotl/gol/pattern"
+)
+
+// A convertFormat is a format gol convert can write, with the extension of
+// the files written
+type convertFormat struct {
+	name, ext string
+	write     func(w io.Writer, p pattern.Pattern) error
+}
+
+var convertFormats = []convertFormat{
+	{"rle", ".rle", pattern.WriteRLE},
+	{"cells", ".cells", pattern.WriteCells},
+	{"mc", ".mc", pattern.WriteMacrocell},
+}
+
+// A conversion converts one pattern file. An empty out is stdout.
+type conversion struct {
+	in, out string
+	err     error
+}
+
+// convert converts a pattern file with the given writer
+func (c *conversion) convert(write func(w io.Writer, p pattern.Pattern) error) error {
+	p, err := pattern.Load(c.in)
+	if err != nil {
+		return err
+	}
+	if c.out == "" {
+		if err := write(os.Stdout, p); err != nil {
+			return fmt.Errorf("%s: %v", c.in, err)
+		}
+		return nil
+	}
+	if c.out == c.in {
+		return fmt.Errorf("%s: would be overwritten by its conversion", c.in)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.out), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(c.out)
+	if err != nil {
+		return err
+	}
+	if err := write(f, p); err != nil {
+		// Rather no file than one missing what did not fit the format
+		f.Close()
+		os.Remove(c.out)
+		return fmt.Errorf("%s: %v", c.in, err)
+	}
+	return f.Close()
+}
+
+// conversions lists the conversions of the files and directories given
+// to gol convert. Directories are searched for pattern files when recurse
+// is set, and their tree is mirrored in the output directory.
+func conversions(inputs []string, out, ext string, recurse bool) ([]conversion, error) {
+	var cs []conversion
+	rename := func(path string) string {
+		return strings.TrimSuffix(path, filepath.Ext(path)) + ext
+	}
+	for _, in := range inputs {
+		info, err := os.Stat(in)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			c := conversion{in: in, out: out}
+			if len(inputs) > 1 || recurse {
+				c.out = filepath.Join(out, rename(filepath.Base(in)))
+			}
+			cs = append(cs, c)
+			continue
+		}
+		if !recurse {
+			return nil, fmt.Errorf("%s is a directory, convert it with -r", in)
+		}
+		err = filepath.WalkDir(in, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !slices.Contains(pattern.Extensions, strings.ToLower(filepath.Ext(path))) {
+				return err
+			}
+			rel, err := filepath.Rel(in, path)
+			if err != nil {
+				return err
+			}
+			cs = append(cs, conversion{in: path, out: filepath.Join(out, rename(rel))})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cs, nil
+}
+
+// runConvert implements the convert subcommand: it converts pattern files,
+// or whole directories of them, to another format
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	names := make([]string, len(convertFormats))
+	for i, f := range convertFormats {
+		names[i] = f.name
+	}
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: gol convert [flags] file or directory...\n\nConverts pattern files in any format gol reads to "+strings.Join(names, ", ")+".\n\n")
+		fs.PrintDefaults()
+	}
+	to := fs.String("to", "rle", "`format` to convert to: "+strings.Join(names, ", "))
+	out := fs.String("out", "", "write the conversion of a single file to `path` instead of stdout; the output directory for several files or -r")
+	recurse := fs.Bool("r", false, "convert all pattern files in the directories given, and below them")
+	workers := fs.Int("workers", cntWorkers, "number of files converted at the same time")
+	fs.Parse(args)
+
+	i := slices.IndexFunc(convertFormats, func(f convertFormat) bool { return f.name == *to })
+	if i < 0 {
+		return fmt.Errorf("unknown format %q, expected one of %s", *to, strings.Join(names, ", "))
+	}
+	format := convertFormats[i]
+	if fs.NArg() == 0 {
+		return fmt.Errorf("nothing to convert, give pattern files or directories")
+	}
+	if *workers < 1 {
+		return fmt.Errorf("invalid number of workers %d", *workers)
+	}
+	if *out == "" && (fs.NArg() > 1 || *recurse) {
+		return fmt.Errorf("converting several files needs an output directory, give it with -out")
+	}
+
+	cs, err := conversions(fs.Args(), *out, format.ext, *recurse)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				cs[i].err = cs[i].convert(format.write)
+			}
+		}()
+	}
+	for i := range cs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var failed []conversion
+	for _, c := range cs {
+		if c.err != nil {
+			failed = append(failed, c)
+		}
+	}
+	if len(cs) > 1 || len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "converted %d of %d patterns to %s\n", len(cs)-len(failed), len(cs), format.name)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	for _, c := range failed {
+		// The errors name the file already
+		fmt.Fprintf(os.Stderr, "  %v\n", c.err)
+	}
+	return fmt.Errorf("%d of %d patterns failed to convert", len(failed), len(cs))
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// A collected pattern is a pattern file found by gol dedupe or gol grep
+type collected struct {
+	path string
+	rule string // normalized, so that B3/S23 and 23/3 are the same
+	p    pattern.Pattern
+}
+
+// dedupeKey groups the patterns that may be duplicates of each other. The
+// canonical cells and the decaying cells around them decide, the hashes
+// just keep the groups small.
+type dedupeKey struct {
+	rule  string
+	hash  uint64
+	decay uint64
+}
+
+// decayHash hashes the decay states of the decaying cells of the pattern,
+// wherever they are, so that it is the same for all orientations of it
+func decayHash(p pattern.Pattern) uint64 {
+	states := make([]byte, 0, len(p.Decaying))
+	for _, s := range p.Decaying {
+		states = append(states, s)
+	}
+	slices.Sort(states)
+	h := fnv.New64a()
+	h.Write(states)
+	return h.Sum64()
+}
+
+// samePattern tells if the canonical pattern a has the live and decaying
+// cells of b in one of the orientations of b. The canonical orientation
+// is chosen by the live cells alone, so every orientation of b is tried.
+func samePattern(a, b pattern.Pattern) bool {
+	q := b
+	for i := 0; i < 8; i++ {
+		if i == 4 {
+			q = b.Flip()
+		}
+		n := q.Normalize()
+		if slices.Equal(slices.Compact(n.Cells), a.Cells) && maps.Equal(n.Decaying, a.Decaying) {
+			return true
+		}
+		q = q.Rotate()
+	}
+	return false
+}
+
+// collectPatterns loads the pattern files among the paths and below the
+// directories among them, in the order of the paths and then of the names.
+// Files that cannot be read are returned as errors.
+func collectPatterns(paths []string) ([]collected, []error) {
+	var cs []collected
+	var errs []error
+	add := func(path string) {
+		p, err := pattern.Load(path)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		rule := engine.Conway.String()
+		if p.Rule != "" {
+			rule = p.Rule
+			if r, err := engine.ParseRule(p.Rule); err == nil {
+				rule = r.String()
+			}
+		}
+		cs = append(cs, collected{path: path, rule: rule, p: p})
+	}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !slices.Contains(pattern.Extensions, strings.ToLower(filepath.Ext(path))) {
+				return err
+			}
+			add(path)
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return cs, errs
+}
+
+// duplicates returns, for every pattern that has duplicates, the pattern
+// followed by its duplicates: the patterns for the same rule with the same
+// live and decaying cells up to symmetry and translation
+func duplicates(cs []collected) [][]collected {
+	groups := make(map[dedupeKey][][]collected)
+	var order []dedupeKey
+	for _, c := range cs {
+		k := dedupeKey{rule: c.rule, hash: c.p.Hash(), decay: decayHash(c.p)}
+		if _, found := groups[k]; !found {
+			order = append(order, k)
+		}
+		i := slices.IndexFunc(groups[k], func(g []collected) bool {
+			return samePattern(g[0].p, c.p)
+		})
+		c.p = c.p.Canonical()
+		if i < 0 {
+			groups[k] = append(groups[k], []collected{c})
+		} else {
+			groups[k][i] = append(groups[k][i], c)
+		}
+	}
+	var dups [][]collected
+	for _, k := range order {
+		for _, g := range groups[k] {
+			if len(g) > 1 {
+				dups = append(dups, g)
+			}
+		}
+	}
+	return dups
+}
+
+// runDedupe implements the dedupe subcommand: it finds the patterns of a
+// collection that are the same up to symmetry and translation, and
+// optionally removes all but the first of each
+func runDedupe(args []string) error {
+	fs := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: gol dedupe [flags] directory or file...\n\nReports the pattern files that are rotations, reflections or translations of\nanother one for the same rule, searching the directories given and below them.\n\n")
+		fs.PrintDefaults()
+	}
+	remove := fs.Bool("delete", false, "delete the duplicates, keeping the first pattern of each in the order of the paths")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("nothing to deduplicate, give pattern directories or files")
+	}
+	cs, errs := collectPatterns(fs.Args())
+
+	dups := duplicates(cs)
+	n := 0
+	for _, g := range dups {
+		fmt.Println(g[0].path)
+		for _, c := range g[1:] {
+			if *remove {
+				if err := os.Remove(c.path); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				fmt.Printf("  %s, deleted\n", c.path)
+			} else {
+				fmt.Printf("  %s\n", c.path)
+			}
+			n++
+		}
+	}
+
+	verb := "found"
+	if *remove {
+		verb = "deleted"
+	}
+	fmt.Fprintf(os.Stderr, "%s %d duplicates of %d patterns among %d patterns\n", verb, n, len(dups), len(cs))
+	if len(errs) == 0 {
+		return nil
+	}
+	for _, err := range errs {
+		// The errors name the file already
+		fmt.Fprintf(os.Stderr, "  %v\n", err)
+	}
+	return fmt.Errorf("could not read or delete %d files", len(errs))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+func TestDuplicates(t *testing.T) {
+	// The spaceship of Brian's Brain, turned, is a duplicate of itself, but
+	// not of the same live cells with the decaying cells on the other side
+	ship := pattern.Pattern{
+		Cells:    []engine.Coord{{X: 1, Y: 0}, {X: 1, Y: 1}},
+		Decaying: map[engine.Coord]uint8{{X: 0, Y: 0}: 1, {X: 0, Y: 1}: 1},
+	}
+	back := pattern.Pattern{
+		Cells:    []engine.Coord{{X: 1, Y: 0}, {X: 1, Y: 1}},
+		Decaying: map[engine.Coord]uint8{{X: 2, Y: 0}: 1, {X: 2, Y: 1}: 1},
+	}
+	other := pattern.Pattern{
+		Cells:    []engine.Coord{{X: 1, Y: 0}, {X: 1, Y: 1}},
+		Decaying: map[engine.Coord]uint8{{X: 0, Y: 0}: 1},
+	}
+	cs := []collected{
+		{path: "ship", rule: "/2/3", p: ship},
+		{path: "turned", rule: "/2/3", p: ship.Rotate().Translate(engine.Coord{X: 5, Y: -2})},
+		{path: "other", rule: "/2/3", p: other},
+		{path: "plain", rule: "/2/3", p: pattern.Pattern{Cells: ship.Cells}},
+		{path: "conway", rule: "B3/S23", p: ship},
+	}
+	dups := duplicates(cs)
+	if len(dups) != 1 || len(dups[0]) != 2 || dups[0][0].path != "ship" || dups[0][1].path != "turned" {
+		t.Errorf("duplicates %v, want ship and turned", dups)
+	}
+
+	// Mirrored, the decaying cells trail on the other side, which is the
+	// same ship flying the other way
+	cs = []collected{{path: "ship", rule: "/2/3", p: ship}, {path: "back", rule: "/2/3", p: back}}
+	if dups := duplicates(cs); len(dups) != 1 {
+		t.Errorf("the ship and its mirror image are not found as duplicates")
+	}
+}
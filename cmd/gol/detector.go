@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// A detector counts the events in a region of the world in every
+// generation, for the -stats rows: the cells born in the region, or, with a
+// pattern file, the occurrences of the pattern appearing with the lower left
+// corner of their bounding box in the region, standing on their own as with
+// -match-exact. The pattern is not rotated or reflected: a glider shows
+// itself reflected two generations later, and would be counted twice. So a
+// detector line one cell wide counts every glider crossing it once.
+type detector struct {
+	spec   string
+	name   string
+	region engine.Region
+	file   string          // pattern file, empty for counting births
+	query  pattern.Pattern // loaded from file
+	m      *pattern.Matcher
+	window engine.Region // the cells deciding about the occurrences in the region
+
+	total       int
+	first, last int // generations of the first and the last event
+}
+
+// parseDetector parses a detector written as name=x0,y0:x1,y1, optionally
+// followed by :file with the pattern to count
+func parseDetector(s string) (detector, error) {
+	d := detector{spec: s}
+	name, where, found := strings.Cut(s, "=")
+	if !found || name == "" {
+		return d, fmt.Errorf("invalid detector %q, expected name=x0,y0:x1,y1[:file]", s)
+	}
+	d.name = name
+	parts := strings.SplitN(where, ":", 3)
+	if len(parts) < 2 {
+		return d, fmt.Errorf("invalid region in detector %q, expected x0,y0:x1,y1", s)
+	}
+	r, err := engine.ParseRegion(parts[0] + ":" + parts[1])
+	if err != nil {
+		return d, fmt.Errorf("detector %s: %v", name, err)
+	}
+	d.region = r
+	if len(parts) == 3 {
+		if parts[2] == "" {
+			return d, fmt.Errorf("detector %q lacks the pattern file after the region", s)
+		}
+		d.file = parts[2]
+	}
+	return d, nil
+}
+
+// load reads the pattern of the detector, if it counts one
+func (d *detector) load() error {
+	if d.file == "" {
+		return nil
+	}
+	p, err := pattern.Load(d.file)
+	if err != nil {
+		return err
+	}
+	if len(p.Cells) == 0 {
+		return fmt.Errorf("%s: nothing to detect, the pattern has no live cells", d.file)
+	}
+	d.watch(p)
+	return nil
+}
+
+// watch makes the detector count the occurrences of the pattern
+func (d *detector) watch(p pattern.Pattern) {
+	d.query, d.m = p, pattern.NewMatcher(p, true)
+	// An occurrence in the region, in any orientation, with the ring of
+	// dead cells around it
+	lo, hi := engine.Bounds(p.Cells)
+	reach := max(hi.X-lo.X, hi.Y-lo.Y) + 1
+	d.window = engine.Region{
+		Min: engine.Coord{X: d.region.Min.X - 1, Y: d.region.Min.Y - 1},
+		Max: engine.Coord{X: d.region.Max.X + reach, Y: d.region.Max.Y + reach},
+	}
+}
+
+// start shows the first generation of the run to the detector, which counts
+// no events in it
+func (d *detector) start(live map[engine.Coord]bool, world engine.World) {
+	if d.m != nil {
+		d.m.Next(d.near(live, world))
+	}
+}
+
+// count returns the events in the region from the generation with the live
+// cells prev to the world, generation gen
+func (d *detector) count(prev, live map[engine.Coord]bool, world engine.World, gen int) int {
+	n := 0
+	if d.m == nil {
+		for c := range live {
+			if !prev[c] && d.region.Contains(c) {
+				n++
+			}
+		}
+	} else {
+		for _, m := range d.m.Next(d.near(live, world)) {
+			if m.Orientation == "r0" && d.region.Contains(m.At) {
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		if d.total == 0 {
+			d.first = gen
+		}
+		d.total += n
+		d.last = gen
+	}
+	return n
+}
+
+// report writes the total of the detector to w with the rate of the events,
+// e.g. of the gliders of a gun, from the first to the last one
+func (d *detector) report(w io.Writer, gens int) {
+	fmt.Fprintf(w, "detector %s: %d %s in %d generations", d.name, d.total, d.what(), gens)
+	if d.total > 1 {
+		fmt.Fprintf(w, ", one every %.1f from generation %d to %d", float64(d.last-d.first)/float64(d.total-1), d.first, d.last)
+	}
+	fmt.Fprintln(w)
+}
+
+// near returns the live cells of the world within the window, the only ones
+// the matcher needs to see
+func (d *detector) near(live map[engine.Coord]bool, world engine.World) engine.World {
+	near := make(engine.World)
+	for c := range live {
+		if d.window.Contains(c) {
+			near[c] = world[c]
+		}
+	}
+	return near
+}
+
+// what describes what the detector counts
+func (d *detector) what() string {
+	if d.file == "" {
+		return "births in " + d.region.String()
+	}
+	return d.query.Name + " in " + d.region.String()
+}
+
+// detectorList collects the -detector flags
+type detectorList []detector
+
+func (l *detectorList) String() string {
+	specs := make([]string, len(*l))
+	for i, d := range *l {
+		specs[i] = d.spec
+	}
+	return strings.Join(specs, ", ")
+}
+
+func (l *detectorList) Set(s string) error {
+	d, err := parseDetector(s)
+	if err != nil {
+		return err
+	}
+	for _, o := range *l {
+		if o.name == d.name {
+			return fmt.Errorf("detector %s is given twice", d.name)
+		}
+	}
+	*l = append(*l, d)
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/miromotl/gol/pattern"
+)
+
+func TestDetector(t *testing.T) {
+	// The Gosper gun fires a glider every 30 generations, the first one
+	// crossing x=40 at generation 99
+	gun, _ := pattern.Named("gosper-gun")
+	glider, _ := pattern.Named("glider")
+	d, err := parseDetector("out=40,-200:40,200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.watch(glider)
+	world := gun.World()
+	live := liveSet(world)
+	d.start(live, world)
+	for gen := 1; gen <= 300; gen++ {
+		world = world.Tick()
+		prev := live
+		live = liveSet(world)
+		d.count(prev, live, world, gen)
+	}
+	if d.total != 7 || d.first != 99 || d.last != 279 {
+		t.Errorf("%d gliders from generation %d to %d, want 7 from 99 to 279", d.total, d.first, d.last)
+	}
+}
+
+func TestParseDetector(t *testing.T) {
+	d, err := parseDetector("out=0,-5:3,5:glider.rle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.name != "out" || d.file != "glider.rle" || d.region.Min.Y != -5 || d.region.Max.X != 3 {
+		t.Errorf("detector reads as %s in %s counting %q", d.name, d.region, d.file)
+	}
+	for _, s := range []string{"0,0:1,1", "=0,0:1,1", "out=0,0", "out=0,0:1,1:", "out=a,0:1,1"} {
+		if _, err := parseDetector(s); err == nil {
+			t.Errorf("%q reads as a detector, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// The map engine keeps all cells in Go maps. An entry of a World costs
+// about this many bytes, including the slack of a growing map.
+const mapBytesPerCell = 64
+
+// calibrationTicks is the number of generations run to estimate the speed
+// of the engine on a given pattern, rounded up to whole frames of -step
+const calibrationTicks = 20
+
+// An estimate predicts the resources a run will need
+type estimate struct {
+	calibrated int           // generations actually run for calibration
+	elapsed    time.Duration // time the calibration took
+	population int           // live cells after calibration
+	memory     int64         // peak bytes for the engine
+	duration   time.Duration // predicted time for the whole run
+}
+
+// estimateRun runs a short calibration burst of the world with the engine
+// and the -step of the run and extrapolates the memory footprint and
+// running time of its ticks frames from it. It assumes the population stays
+// about where it is after the burst, which is true for most soups once the
+// initial explosion has settled.
+func estimateRun(cfg config, world engine.World) estimate {
+	var e estimate
+
+	// Calibrated in whole frames, because Hashlife takes about as long for
+	// a frame of many generations as for one of few
+	frames := min((calibrationTicks+cfg.step-1)/cfg.step, cfg.ticks)
+	cfg.detectCycle, cfg.phaseTimings = 0, false
+
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	sim := newSimulation(cfg, world, cfg.start)
+	for i := 1; i <= frames; i++ {
+		sim.advance(cfg.start + i*cfg.step)
+	}
+	e.calibrated = frames * cfg.step
+	e.elapsed = time.Since(start)
+	e.population = len(sim.world.LiveCells())
+
+	if cfg.hashlife || cfg.incremental || cfg.dense {
+		// The engine holds its own form of the world, which is measured
+		var after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		e.memory = max(int64(after.HeapAlloc)-int64(before.HeapAlloc), 0)
+		runtime.KeepAlive(sim)
+	} else {
+		// During a tick the inflated world and the counted copy of it are
+		// alive at the same time, next to the input and the result
+		halo := len(sim.world.Inflate())
+		e.memory = int64(2*halo+2*e.population) * mapBytesPerCell
+	}
+
+	if frames > 0 {
+		e.duration = e.elapsed / time.Duration(frames) * time.Duration(cfg.ticks)
+	}
+
+	return e
+}
+
+func (e estimate) String() string {
+	return fmt.Sprintf("about %s of memory and %s for the run (calibrated on %d generations in %s, %d live cells)",
+		formatBytes(e.memory), e.duration.Round(time.Millisecond), e.calibrated, e.elapsed.Round(time.Microsecond), e.population)
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
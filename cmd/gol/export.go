@@ -0,0 +1,258 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// An exporter writes the generations of a run to a file for use by other
+// tools. export is called for every emitted generation, close once after
+// the last one.
+type exporter interface {
+	export(world engine.World, gen int) error
+	close() error
+}
+
+// newExporters creates the exporters requested on the command line for a
+// run starting from the given world
+func newExporters(cfg config, world engine.World) ([]exporter, error) {
+	var exporters []exporter
+	if cfg.stats != "" {
+		e, err := newStatsExporter(cfg.stats, world, cfg.start, cfg.detectors)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, e)
+	}
+	if len(cfg.match.Cells) > 0 {
+		e, err := newMatchExporter(cfg.matchCSV, cfg.match, cfg.matchExact, world, cfg.start)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, e)
+	}
+	if cfg.exportMtx != "" {
+		exporters = append(exporters, &snapshotExporter{path: cfg.exportMtx, format: writeMatrix})
+	}
+	if cfg.exportCells != "" {
+		exporters = append(exporters, &snapshotExporter{path: cfg.exportCells, format: writeCellsSnapshot})
+	}
+	if cfg.exportCSV != "" {
+		e, err := newCSVExporter(cfg.exportCSV)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, e)
+	}
+	if cfg.exportParquet != "" {
+		e, err := newParquetExporter(cfg.exportParquet)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, e)
+	}
+	if cfg.exportASCII != "" {
+		e, err := newASCIIExporter(cfg.exportASCII, cfg)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, e)
+	}
+	return exporters, nil
+}
+
+// csvExporter writes a gen,x,y row for every live cell of every generation
+type csvExporter struct {
+	f *os.File
+	w *csv.Writer
+}
+
+func newCSVExporter(path string) (*csvExporter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &csvExporter{f, csv.NewWriter(f)}
+	e.w.Write([]string{"gen", "x", "y"})
+	return e, nil
+}
+
+func (e *csvExporter) export(world engine.World, gen int) error {
+	g := strconv.Itoa(gen)
+	for _, c := range world.LiveCells() {
+		e.w.Write([]string{g, strconv.Itoa(c.X), strconv.Itoa(c.Y)})
+	}
+	return e.w.Error()
+}
+
+func (e *csvExporter) close() error {
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
+
+// snapshotExporter writes single generations to files. If the path
+// contains a %d verb every generation is written to its own file,
+// otherwise only the last generation is written.
+type snapshotExporter struct {
+	path   string
+	format func(w io.Writer, path string, world engine.World, gen int) error
+	last   engine.World
+	gen    int
+}
+
+func (e *snapshotExporter) export(world engine.World, gen int) error {
+	if strings.Contains(e.path, "%") {
+		return e.write(fmt.Sprintf(e.path, gen), world, gen)
+	}
+	e.last, e.gen = world, gen
+	return nil
+}
+
+func (e *snapshotExporter) close() error {
+	if e.last == nil {
+		return nil
+	}
+	return e.write(e.path, e.last, e.gen)
+}
+
+func (e *snapshotExporter) write(path string, world engine.World, gen int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := e.format(f, path, world, gen); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeMatrix writes the world as a sparse matrix, in Matrix Market
+// format or, if the file name ends in .npz, as a SciPy sparse COO matrix
+func writeMatrix(w io.Writer, path string, world engine.World, gen int) error {
+	if strings.HasSuffix(path, ".npz") {
+		return writeNPZ(w, world)
+	}
+	return writeMatrixMarket(w, world, gen)
+}
+
+// writeCellsSnapshot writes the live cells of the world as a plaintext
+// .cells pattern, or as a Golly macrocell if the path ends in .mc. The
+// macrocell keeps the cells where they are.
+func writeCellsSnapshot(w io.Writer, path string, world engine.World, gen int) error {
+	if strings.HasSuffix(path, ".mc") {
+		p := pattern.Pattern{Name: fmt.Sprintf("generation %d", gen), Cells: world.LiveCells()}
+		return pattern.WriteMacrocell(w, p)
+	}
+	p := pattern.FromWorld(world)
+	p.Name = fmt.Sprintf("generation %d", gen)
+	return pattern.WriteCells(w, p)
+}
+
+// writeMatrixMarket writes the live cells of the world as a Matrix Market
+// coordinate matrix. Rows are y and columns are x, shifted so that the
+// bounding box of the live cells starts at (1, 1); the original origin is
+// recorded in a comment.
+func writeMatrixMarket(w io.Writer, world engine.World, gen int) error {
+	coords := world.LiveCells()
+	min, max := engine.Bounds(coords)
+	rows, cols := max.Y-min.Y+1, max.X-min.X+1
+	if len(coords) == 0 {
+		rows, cols = 0, 0
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "%%MatrixMarket matrix coordinate integer general")
+	fmt.Fprintf(bw, "%% gol generation %d, row 1 is y=%d, column 1 is x=%d\n", gen, min.Y, min.X)
+	fmt.Fprintf(bw, "%d %d %d\n", rows, cols, len(coords))
+	for _, c := range coords {
+		fmt.Fprintf(bw, "%d %d 1\n", c.Y-min.Y+1, c.X-min.X+1)
+	}
+	return bw.Flush()
+}
+
+// writeNPZ writes the live cells of the world in the layout of
+// scipy.sparse.save_npz for a COO matrix, so it can be read back with
+// scipy.sparse.load_npz. Rows and columns are shifted as in
+// writeMatrixMarket.
+func writeNPZ(w io.Writer, world engine.World) error {
+	coords := world.LiveCells()
+	min, max := engine.Bounds(coords)
+	rows, cols := max.Y-min.Y+1, max.X-min.X+1
+	if len(coords) == 0 {
+		rows, cols = 0, 0
+	}
+
+	row := make([]int32, len(coords))
+	col := make([]int32, len(coords))
+	data := make([]int8, len(coords))
+	for i, c := range coords {
+		row[i] = int32(c.Y - min.Y)
+		col[i] = int32(c.X - min.X)
+		data[i] = 1
+	}
+
+	arrays := []struct {
+		name  string
+		descr string
+		shape string
+		data  interface{}
+	}{
+		{"row", "<i4", fmt.Sprintf("(%d,)", len(row)), row},
+		{"col", "<i4", fmt.Sprintf("(%d,)", len(col)), col},
+		{"data", "|i1", fmt.Sprintf("(%d,)", len(data)), data},
+		{"shape", "<i8", "(2,)", []int64{int64(rows), int64(cols)}},
+		{"format", "|S3", "()", []byte("coo")},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, a := range arrays {
+		f, err := zw.Create(a.name + ".npy")
+		if err != nil {
+			return err
+		}
+		if err := writeNPY(f, a.descr, a.shape, a.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeNPY writes a single NumPy array in .npy format version 1.0. data
+// is written with binary.Write and has to match descr.
+func writeNPY(w io.Writer, descr, shape string, data interface{}) error {
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", descr, shape)
+	// The magic string, version and header length take 10 bytes, the whole
+	// preamble is padded with spaces to a multiple of 64 and ends in '\n'
+	pad := 64 - (10+len(header)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+	header += strings.Repeat(" ", pad) + "\n"
+
+	var buf bytes.Buffer
+	buf.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+		return err
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/miromotl/gol/pattern"
+)
+
+// maxGrepPlaces is the most places of a match listed for a pattern
+const maxGrepPlaces = 5
+
+// runGrep implements the grep subcommand: it lists the patterns of a
+// collection, or of the catalog, that contain a shape in any rotation or
+// reflection
+func runGrep(args []string) error {
+	fs := flag.NewFlagSet("grep", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: gol grep -shape file [flags] [directory or file...]\n\nLists the patterns containing the shape, rotated or reflected in any way, with\nthe places it was found at, searching the directories given and below them, or\nthe catalog of well-known patterns if none are given.\n\n")
+		fs.PrintDefaults()
+	}
+	shape := fs.String("shape", "", "pattern `file` with the shape to search for")
+	exact := fs.Bool("exact", false, "only match where the shape stands on its own: the other cells of its bounding box and the cells around the box are dead")
+	fs.Parse(args)
+
+	if *shape == "" {
+		return fmt.Errorf("nothing to search for, give the shape with -shape")
+	}
+	query, err := pattern.Load(*shape)
+	if err != nil {
+		return err
+	}
+	if len(query.Cells) == 0 {
+		return fmt.Errorf("%s: the shape has no live cells", *shape)
+	}
+
+	var cs []collected
+	var errs []error
+	if fs.NArg() == 0 {
+		for _, name := range pattern.Names() {
+			p, err := pattern.Named(name)
+			if err != nil {
+				return err
+			}
+			cs = append(cs, collected{path: name, p: p})
+		}
+	} else {
+		cs, errs = collectPatterns(fs.Args())
+	}
+
+	n := 0
+	for _, c := range cs {
+		places := c.p.Normalize().Find(query, *exact)
+		if len(places) == 0 {
+			continue
+		}
+		n++
+		list := make([]string, 0, maxGrepPlaces)
+		for _, at := range places[:min(len(places), maxGrepPlaces)] {
+			list = append(list, fmt.Sprintf("%d,%d", at.X, at.Y))
+		}
+		if len(places) > maxGrepPlaces {
+			list = append(list, "...")
+		}
+		fmt.Printf("%s: %d at %s\n", c.path, len(places), strings.Join(list, " "))
+	}
+
+	for _, err := range errs {
+		// The errors name the file already
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("could not read %d files", len(errs))
+	}
+	if n == 0 {
+		return fmt.Errorf("none of %d patterns contains %s", len(cs), query.Name)
+	}
+	return nil
+}
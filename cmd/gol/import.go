@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// importProblems checks a pattern loaded for a run against the
+// configuration of the run: cells listed more than once, cells outside the
+// torus or region the run is confined to, with a hint to -center if the
+// pattern fits onto the torus, cells folding onto each other on the torus,
+// decaying cells in states the rule does not have, and a rule given with
+// -rule that is not the one in the pattern file. ruleFlag is the value of
+// -rule.
+func importProblems(p pattern.Pattern, cfg config, ruleFlag string) []string {
+	var problems []string
+	report := func(cells []engine.Coord, what string) {
+		if len(cells) > 0 {
+			c := cells[0]
+			problems = append(problems, fmt.Sprintf("%s: %s: %d, e.g. %d,%d", p.Name, what, len(cells), c.X, c.Y))
+		}
+	}
+
+	seen := make(map[engine.Coord]bool, len(p.Cells))
+	var duplicates []engine.Coord
+	for _, c := range p.Cells {
+		if seen[c] {
+			duplicates = append(duplicates, c)
+		}
+		seen[c] = true
+	}
+	report(duplicates, "cells listed more than once")
+
+	if t := cfg.engine.Torus; t != nil {
+		lo, hi := p.Bounds()
+		fits := hi.X-lo.X < t.Width && hi.Y-lo.Y < t.Height
+		folded := make(map[engine.Coord]bool, len(seen))
+		var outside, overlaps []engine.Coord
+		for _, c := range p.Cells {
+			w := t.Wrap(c)
+			if w != c {
+				outside = append(outside, c)
+				if seen[w] || folded[w] {
+					overlaps = append(overlaps, c)
+				}
+			}
+			folded[w] = true
+		}
+		report(outside, fmt.Sprintf("cells outside the %s, folded onto it", t))
+		if len(outside) > 0 && fits {
+			problems[len(problems)-1] += "; -center moves the whole pattern onto it"
+		}
+		report(overlaps, "cells folded onto other cells of the pattern")
+	}
+
+	if r := cfg.region; r != nil {
+		var outside []engine.Coord
+		for _, c := range p.Cells {
+			if t := cfg.engine.Torus; t != nil {
+				c = t.Wrap(c)
+			}
+			if !r.Contains(c) {
+				outside = append(outside, c)
+			}
+		}
+		report(outside, fmt.Sprintf("cells outside the region %s, which stay dead", r))
+	}
+
+	var undecayable []engine.Coord
+	for c, state := range p.Decaying {
+		if int(state) > cfg.engine.Rule.States-2 {
+			undecayable = append(undecayable, c)
+		}
+	}
+	engine.SortCoords(undecayable)
+	report(undecayable, fmt.Sprintf("decaying cells in states rule %s does not have, which die", cfg.engine.Rule))
+
+	if ruleFlag != "" && p.Rule != "" {
+		if r, err := engine.ParseRule(p.Rule); err == nil && r.String() != cfg.engine.Rule.String() {
+			problems = append(problems, fmt.Sprintf("%s: the pattern is meant for rule %s, not %s", p.Name, r, cfg.engine.Rule))
+		}
+	}
+	return problems
+}
+
+// centered returns the pattern moved so that the bounding box of its live
+// cells is centred on the origin, as the cells of a torus are. A pattern no
+// larger than the torus then lies on it as a whole.
+func centered(p pattern.Pattern) pattern.Pattern {
+	if len(p.Cells) == 0 {
+		return p
+	}
+	lo, hi := p.Bounds()
+	return p.Translate(engine.Coord{X: -(hi.X-lo.X+1)/2 - lo.X, Y: -(hi.Y-lo.Y+1)/2 - lo.Y})
+}
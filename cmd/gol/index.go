@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/miromotl/gol/render"
+)
+
+// indexPreviewLines is the number of lines of a text file shown in the
+// index, and indexThumbnails the number of frames of a sequence
+const (
+	indexPreviewLines = 10
+	indexPreviewWidth = 200
+	indexThumbnails   = 8
+)
+
+// An artifact is a file, or a sequence of files, written by a run
+type artifact struct {
+	what  string
+	paths []string
+}
+
+// runArtifacts lists the files a run wrote, in the order of the flags.
+// A path with a %d is written once for every generation in gens. Files
+// that do not exist, e.g. because the run failed early, are left out.
+func runArtifacts(cfg config, gens []int) []artifact {
+	var artifacts []artifact
+	add := func(what, path string) {
+		if path == "" {
+			return
+		}
+		a := artifact{what: what}
+		names := []string{path}
+		if strings.Contains(path, "%") {
+			names = names[:0]
+			for _, gen := range gens {
+				names = append(names, fmt.Sprintf(path, gen))
+			}
+		}
+		for _, name := range names {
+			if _, err := os.Stat(name); err == nil {
+				a.paths = append(a.paths, name)
+			}
+		}
+		if len(a.paths) > 0 {
+			artifacts = append(artifacts, a)
+		}
+	}
+
+	if cfg.serve == "" && !cfg.interactive {
+		path := cfg.outputPath
+		if cfg.output == "png" && path == "" {
+			path = pngFramePath
+		}
+		add(cfg.output+" output", path)
+	}
+	add("statistics", cfg.stats)
+	add("sparse matrix", cfg.exportMtx)
+	add("plaintext pattern", cfg.exportCells)
+	add("CSV cell list", cfg.exportCSV)
+	add("Parquet cell list", cfg.exportParquet)
+	add("text frames", cfg.exportASCII)
+	add("state to resume from", cfg.save)
+	return artifacts
+}
+
+// indexDir is the directory the index of a run goes into: the one the
+// output is written to
+func indexDir(cfg config) string {
+	return filepath.Dir(cfg.outputPath)
+}
+
+// An indexFile is a file as shown in the index
+type indexFile struct {
+	Link    string // relative to the index
+	Image   bool
+	Preview string // the first lines of a text file
+}
+
+// An indexEntry is an artifact as shown in the index
+type indexEntry struct {
+	What   string
+	Files  []indexFile // all files of the artifact
+	Shown  []indexFile // the files previewed
+	Hidden int         // files not previewed
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gol run: {{.Title}}</title>
+<style>
+  body { font-family: sans-serif; margin: 2em; }
+  table { border-collapse: collapse; }
+  td { padding: 0.1em 1em 0.1em 0; }
+  pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }
+  img { max-width: 320px; image-rendering: pixelated; border: 1px solid #ccc; margin: 0.2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+{{range .Summary}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{range .Entries}}
+<h2>{{.What}}</h2>
+{{range .Shown}}{{if .Image}}<a href="{{.Link}}"><img src="{{.Link}}" alt="{{.Link}}"></a>
+{{else}}<p><a href="{{.Link}}">{{.Link}}</a></p>
+{{if .Preview}}<pre>{{.Preview}}</pre>
+{{end}}{{end}}{{end}}{{if .Hidden}}<details><summary>all {{len .Files}} files</summary>
+<ul>
+{{range .Files}}<li><a href="{{.Link}}">{{.Link}}</a></li>
+{{end}}</ul>
+</details>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// writeIndex writes an index.html into dir, with a summary of the run
+// and links to and previews of its artifacts
+func writeIndex(dir string, meta render.Metadata, final worldStats, artifacts []artifact) error {
+	type pair struct{ Key, Value string }
+	data := struct {
+		Title   string
+		Summary []pair
+		Entries []indexEntry
+	}{}
+	for _, m := range meta {
+		if m.Key == "pattern" {
+			data.Title = m.Value
+		}
+		data.Summary = append(data.Summary, pair{m.Key, m.Value})
+	}
+	data.Summary = append(data.Summary,
+		pair{"final generation", fmt.Sprint(final.gen)},
+		pair{"final population", fmt.Sprint(final.pop)})
+
+	for _, a := range artifacts {
+		e := indexEntry{What: a.what}
+		for _, path := range a.paths {
+			e.Files = append(e.Files, newIndexFile(dir, path, false))
+		}
+		// Of a sequence of frames only a few evenly spread ones are shown
+		shown := a.paths
+		if len(shown) > indexThumbnails {
+			shown = nil
+			for i := 0; i < indexThumbnails; i++ {
+				shown = append(shown, a.paths[i*(len(a.paths)-1)/(indexThumbnails-1)])
+			}
+		}
+		for _, path := range shown {
+			e.Shown = append(e.Shown, newIndexFile(dir, path, true))
+		}
+		e.Hidden = len(a.paths) - len(shown)
+		data.Entries = append(data.Entries, e)
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return err
+	}
+	if err := indexTemplate.Execute(f, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// newIndexFile describes a file for the index in dir, reading the start
+// of it for a preview if asked to
+func newIndexFile(dir, path string, preview bool) indexFile {
+	link := path
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		link = filepath.ToSlash(rel)
+	}
+	f := indexFile{Link: link}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".gif", ".svg":
+		f.Image = true
+	case ".parquet", ".npz":
+		// Binary, there is nothing to preview
+	default:
+		if preview {
+			f.Preview = previewText(path)
+		}
+	}
+	return f
+}
+
+// previewText returns the first lines of a text file, each cut short
+func previewText(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	var b strings.Builder
+	s := bufio.NewScanner(file)
+	s.Buffer(nil, 1<<20)
+	for i := 0; i < indexPreviewLines && s.Scan(); i++ {
+		line := s.Text()
+		if len(line) > indexPreviewWidth {
+			line = line[:indexPreviewWidth] + "…"
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// A controller lets the user steer an interactive run from the keyboard
+// of the terminal the run is shown in
+type controller struct {
+	term      *termRenderer
+	keys      chan string
+	interrupt chan os.Signal
+	paused    bool
+	restore   func()
+}
+
+// Bounds of the delay between two generations of an interactive run
+const (
+	minFrameDelay = 5 * time.Millisecond
+	maxFrameDelay = 5 * time.Second
+)
+
+// newController puts the terminal into a mode where every key press is
+// read right away and not echoed, and starts reading the keys
+func newController(term *termRenderer) (*controller, error) {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return nil, fmt.Errorf("interactive mode needs a terminal: %v", err)
+	}
+	restore, err := rawTerminal(tty)
+	if err != nil {
+		tty.Close()
+		return nil, err
+	}
+
+	c := &controller{term: term, keys: make(chan string, 16), interrupt: make(chan os.Signal, 1)}
+	c.restore = func() {
+		restore()
+		tty.Close()
+	}
+
+	// Ctrl-C quits like q does, so the terminal gets restored
+	signal.Notify(c.interrupt, os.Interrupt)
+
+	go c.read(tty)
+	c.showHelp()
+	return c, nil
+}
+
+// rawTerminal switches off line buffering and echo of the terminal with
+// stty and returns the function switching them back on
+func rawTerminal(tty *os.File) (func(), error) {
+	stty := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = tty
+		return cmd.Output()
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("stty: %v", err)
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, fmt.Errorf("stty: %v", err)
+	}
+	return func() { stty(string(bytes.TrimSpace(saved))) }, nil
+}
+
+// read turns the bytes typed into keys: single characters, or up, down,
+// left and right for the arrow keys
+func (c *controller) read(tty *os.File) {
+	arrows := map[byte]string{'A': "up", 'B': "down", 'C': "right", 'D': "left"}
+	buf := make([]byte, 16)
+	for {
+		n, err := tty.Read(buf)
+		if err != nil {
+			close(c.keys)
+			return
+		}
+		for i := 0; i < n; i++ {
+			if buf[i] == 0x1b && i+2 < n && buf[i+1] == '[' {
+				if k, found := arrows[buf[i+2]]; found {
+					c.keys <- k
+				}
+				i += 2
+				continue
+			}
+			c.keys <- string(buf[i])
+		}
+	}
+}
+
+// between is called after every generation. It handles the keys pressed
+// in the meantime, and while the run is paused waits for the user to
+// resume it or step it on. It tells if the user wants to quit.
+func (c *controller) between() (quit bool) {
+	for {
+		var k string
+		var ok bool
+		if c.paused {
+			select {
+			case k, ok = <-c.keys:
+			case <-c.interrupt:
+				return true
+			}
+		} else {
+			select {
+			case k, ok = <-c.keys:
+			case <-c.interrupt:
+				return true
+			default:
+				return false
+			}
+		}
+		if !ok {
+			// No more keys will come, let the run finish on its own
+			c.paused = false
+			return false
+		}
+
+		pan := c.term.h/5 + 1
+		switch strings.ToLower(k) {
+		case "q":
+			return true
+		case " ":
+			c.paused = !c.paused
+		case "n":
+			if c.paused {
+				return false
+			}
+		case "+", "=":
+			c.term.delay = max(c.term.delay/2, minFrameDelay)
+		case "-":
+			c.term.delay = min(c.term.delay*2, maxFrameDelay)
+		case "up":
+			c.term.pan.Y += pan
+		case "down":
+			c.term.pan.Y -= pan
+		case "left":
+			c.term.pan.X -= pan
+		case "right":
+			c.term.pan.X += pan
+		default:
+			continue
+		}
+		c.showHelp()
+		c.term.draw()
+	}
+}
+
+// showHelp puts the state of the run and the keys on the status line
+func (c *controller) showHelp() {
+	if c.paused {
+		c.term.note = " | paused: space resume, n step, arrows pan, q quit"
+	} else {
+		c.term.note = fmt.Sprintf(" | %s per generation: space pause, +/- speed, arrows pan, q quit", c.term.delay)
+	}
+}
+
+func (c *controller) close() {
+	signal.Reset(os.Interrupt)
+	c.restore()
+}
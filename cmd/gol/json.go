@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// A jsonFrame is one generation written by the json output
+type jsonFrame struct {
+	Generation int          `json:"generation"`
+	Cells      engine.World `json:"cells"` // all live cells, not just the visible ones
+}
+
+// jsonRenderer writes every generation as a JSON object on a line of its
+// own, for scripts to read one generation at a time
+type jsonRenderer struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONRenderer(w io.Writer) *jsonRenderer {
+	bw := bufio.NewWriter(w)
+	return &jsonRenderer{bw, json.NewEncoder(bw)}
+}
+
+func (r *jsonRenderer) Render(world engine.World, gen int) error {
+	if err := r.enc.Encode(jsonFrame{Generation: gen, Cells: world}); err != nil {
+		return err
+	}
+	// Flush, so that a script reading the output sees every generation
+	// as soon as it is computed
+	return r.w.Flush()
+}
+
+func (r *jsonRenderer) Close() error {
+	return r.w.Flush()
+}
+
+// readJSONPattern reads a world written as a list of [x, y] pairs, or as a
+// frame of the json output, from r
+func readJSONPattern(r io.Reader, name string) (pattern.Pattern, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return pattern.Pattern{}, fmt.Errorf("%s: %v", name, err)
+	}
+	var world engine.World
+	if len(raw) > 0 && raw[0] == '{' {
+		var frame jsonFrame
+		if err := json.Unmarshal(raw, &frame); err != nil {
+			return pattern.Pattern{}, fmt.Errorf("%s: %v", name, err)
+		}
+		world = frame.Cells
+	} else if err := json.Unmarshal(raw, &world); err != nil {
+		return pattern.Pattern{}, fmt.Errorf("%s: %v", name, err)
+	}
+	return pattern.Pattern{Name: name, Cells: world.LiveCells()}, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+func TestJSONFrame(t *testing.T) {
+	// A frame of the json output has to read back as the world written
+	world := make(engine.World)
+	engine.FillRandomSoup(world, 1, 64, 1)
+	world = world.Tick()
+	var b bytes.Buffer
+	r := newJSONRenderer(&b)
+	if err := r.Render(world, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p, err := readJSONPattern(&b, "frame")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(p.Cells, world.LiveCells()) {
+		t.Errorf("%d cells read back as %d cells", len(world.LiveCells()), len(p.Cells))
+	}
+
+	// A plain list of pairs reads as well
+	p, err = readJSONPattern(strings.NewReader("[[0,0],[1,0],[2,0]]"), "blinker")
+	if err != nil || len(p.Cells) != 3 {
+		t.Errorf("blinker reads as %v, %v", p.Cells, err)
+	}
+	if _, err := readJSONPattern(strings.NewReader("{"), "broken"); err == nil {
+		t.Errorf("broken JSON reads as a pattern")
+	}
+}
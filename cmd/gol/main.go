@@ -0,0 +1,679 @@
+// Implementing Conway's Game Of Life
+// ----------------------------------
+//
+// The simulation itself lives in packages engine and pattern, this is the
+// command line front end for them.
+//
+// We are printing the successive populations in a format that can be fed
+// to gnuplot and creating in this way an animated view of the population.
+//
+// This is just an exercise for using maps in go! Do not take this
+// too serious...
+//
+// To see the simulation in gnuplot, call the program like this:
+// ./gol | gnuplot --persist
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+	"github.com/miromotl/gol/render"
+)
+
+// We use as many go routines as workes as there are cores/processors
+// in the computer, unless -workers says otherwise.
+var cntWorkers = runtime.NumCPU()
+
+func main() {
+	// Subcommands have their own flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "phase":
+			if err := runPhase(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "perturb":
+			if err := runPerturb(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "agar":
+			if err := runAgar(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "wick":
+			if err := runWick(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "torus":
+			if err := runTorus(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "convert":
+			if err := runConvert(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "dedupe":
+			if err := runDedupe(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "grep":
+			if err := runGrep(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "bench":
+			if err := runBench(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "selftest":
+			if err := runSelftest(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	// Handle the command line arguments
+	cfg := handleCommandLine()
+
+	if cfg.verifyRules {
+		mismatches := engine.VerifyRules(cfg.engine.Rule)
+		for _, m := range mismatches {
+			fmt.Println(m)
+		}
+		fmt.Printf("checked 512 neighbourhoods, %d mismatches\n", len(mismatches))
+		if len(mismatches) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The world
+	var world engine.World
+	world = make(engine.World)
+
+	if cfg.random && cfg.ash {
+		engine.FillAsh(world, newRNG(cfg.rng, cfg.seed), cfg.size, cfg.ashDensity)
+	} else if cfg.random {
+		engine.FillSoupDensity(world, newRNG(cfg.rng, cfg.seed), cfg.size, cfg.engine.Workers, cfg.density)
+	} else {
+		cfg.pattern.Place(world, engine.Coord{})
+		for c, cell := range cfg.decaying {
+			if !world[c].Alive {
+				world[c] = cell
+			}
+		}
+	}
+	if cfg.engine.Torus != nil {
+		world = cfg.engine.Torus.Fold(world)
+	}
+
+	// A random run can only be repeated with its seed
+	if cfg.random && !cfg.dryRun {
+		fmt.Fprintf(os.Stderr, "seed %d\n", cfg.seed)
+	}
+
+	if cfg.dryRun {
+		printPlan(os.Stdout, cfg, world)
+		if cfg.estimate {
+			fmt.Printf("  estimate:    %s\n", estimateRun(cfg, world))
+		}
+		return
+	}
+
+	if cfg.estimate {
+		fmt.Fprintf(os.Stderr, "estimate: %s\n", estimateRun(cfg, world))
+	}
+
+	if err := run(cfg, world); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// config holds everything the command line tells us about the run
+type config struct {
+	ticks   int
+	size    int
+	pattern pattern.Pattern
+	render  render.Options
+
+	output      string        // format of the rendered generations, see outputNames
+	outputPath  string        // file the rendered generations go to, stdout if empty
+	frameDelay  time.Duration // time between two frames of an animation
+	interpolate int           // frames per generation in an animation
+
+	random bool   // the pattern is a random soup
+	seed   int64  // seed of the random soup
+	rng    string // generator of the random soup, see rngNames
+	ash    bool   // the random pattern is an ash field instead of a soup
+
+	density    float64 // probability of a live cell in the soup
+	ashDensity float64 // probability of an object in a slot of the ash field
+
+	dryRun         bool   // print the plan instead of running
+	estimate       bool   // predict memory and time before running
+	phaseTimings   bool   // report the time spent in each phase of a tick
+	debugAllocs    bool   // report the heap allocations of the generations
+	trace          string // runtime trace file
+	prune          engine.PrunePolicy
+	dropFrames     bool           // skip generations the render.Renderer cannot keep up with
+	maxGPS         float64        // generations per second, 0 for no limit
+	drift          drift          // velocity subtracted from the displayed world
+	region         *engine.Region // only cells in here are simulated, nil for all
+	strictImport   bool           // problems found loading the pattern are errors, not warnings
+	wrapMargin     int            // on a torus, warn when the live cells come this close to wrapping into themselves, 0 for never
+	strictTopology bool           // stop the run instead of warning with wrapMargin
+	engine         engine.Engine  // rule and topology of the world
+	hashlife       bool           // compute the generations with Hashlife instead of the map engine
+	incremental    bool           // compute the generations with engine.Incremental
+	dense          bool           // compute the generations on a bitboard with engine.Dense
+	interactive    bool           // show the run in the terminal and let the user steer it
+	serve          string         // address serving the run to browsers, instead of the output
+	step           int            // generations from one frame to the next
+	detectCycle    int            // generations searched for a repetition, 0 to run all ticks
+	verifyRules    bool           // check the engine against the rule table and exit
+	alerts         alertList      // conditions that stop the run or call hooks
+	detectors      detectorList   // regions whose events are counted in the -stats rows
+	webhook        string         // URL told about the end of the run and notify alerts
+	index          bool           // write an index.html of the files written next to the output
+	save           string         // state file written at the end of the run
+	resume         string         // state file the run continues from
+	start          int            // generation the run starts at, after -resume
+	decaying       engine.World   // decaying cells the run starts with, after -resume
+
+	exportMtx     string          // sparse matrix file, see writeMatrix
+	exportCells   string          // plaintext pattern file, see snapshotExporter
+	exportParquet string          // Parquet file with all generations
+	exportCSV     string          // CSV file with all generations
+	exportASCII   string          // text frames or asciinema recording of all generations
+	stats         string          // CSV file with statistics of all generations, - for stderr
+	match         pattern.Pattern // query whose occurrences are reported, without cells for none
+	matchCSV      string          // CSV file with the occurrences of the query, - for stderr
+	matchExact    bool            // only report occurrences standing on their own
+}
+
+func handleCommandLine() (cfg config) {
+	// Define our own usage message, overwriting the default one
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: cgol [flags] [pattern] | gnuplot --persist\n")
+		fmt.Fprint(os.Stderr, "       cgol -output gif -o out.gif [flags] [pattern]\n")
+		fmt.Fprint(os.Stderr, "       cgol -output term [flags] [pattern]\n")
+		fmt.Fprint(os.Stderr, "       cgol -interactive [flags] [pattern]\n")
+		fmt.Fprint(os.Stderr, "       cgol -serve :8080 [flags] [pattern]\n")
+		fmt.Fprint(os.Stderr, "       cgol -output png -o frame_%04d.png [flags] [pattern]\n")
+		fmt.Fprint(os.Stderr, "       cgol -resume state.json -save state.json [flags]\n")
+		fmt.Fprint(os.Stderr, "       cgol phase [flags]\n")
+		fmt.Fprint(os.Stderr, "       cgol perturb [flags]\n")
+		fmt.Fprint(os.Stderr, "       cgol agar [flags]\n")
+		fmt.Fprint(os.Stderr, "       cgol wick [flags]\n")
+		fmt.Fprint(os.Stderr, "       cgol torus [flags]\n")
+		fmt.Fprint(os.Stderr, "       cgol convert [flags] file or directory...\n")
+		fmt.Fprint(os.Stderr, "       cgol dedupe [flags] directory or file...\n")
+		fmt.Fprint(os.Stderr, "       cgol grep -shape file [flags] [directory or file...]\n")
+		fmt.Fprint(os.Stderr, "       cgol bench [flags]\n")
+		fmt.Fprint(os.Stderr, "       cgol selftest\n")
+		flag.PrintDefaults()
+	}
+
+	// Define the command line flags
+	flag.IntVar(&cfg.ticks, "ticks", 10, "number of iterations running the game")
+	flag.IntVar(&cfg.step, "step", 1, "advance `n` generations in every iteration, only the last one is rendered and exported")
+	flag.IntVar(&cfg.detectCycle, "detect-cycle", 0, "stop once the world repeats one of the last `n` generations, as still lifes and oscillators do, and report the period")
+	var engineOpt *string = flag.String("engine", "map", "engine computing the generations: map, incremental for worlds with few active spots, dense for soups on a torus, or hashlife for long runs of large patterns")
+	flag.IntVar(&cfg.size, "size", 50, "size of the visible world in x and y direction")
+	flag.BoolVar(&cfg.random, "random", false, "generate a random pattern to start with")
+	flag.BoolVar(&cfg.ash, "ash", false, "generate a random ash field of small still lifes and oscillators to start with")
+	flag.Float64Var(&cfg.density, "density", 0.2, "probability of a live cell in the random soup")
+	flag.Float64Var(&cfg.ashDensity, "ash-density", 0.5, "probability of an object in each 7x7 slot of the ash field")
+	flag.Int64Var(&cfg.seed, "seed", 0, "seed for the random pattern, 0 picks one at random")
+	flag.StringVar(&cfg.rng, "rng", "math", "random number generator of the random pattern: "+strings.Join(rngNames, ", "))
+	loadPattern := patternFlags(flag.CommandLine)
+	flag.BoolVar(&cfg.strictImport, "strict-import", false, "refuse to run a pattern with duplicate cells, cells outside the torus or region, or a rule other than -rule, instead of warning about them")
+	flag.StringVar(&cfg.save, "save", "", "write the world, generation, seed and rule at the end of the run to a JSON state `file`")
+	flag.StringVar(&cfg.resume, "resume", "", "continue the run saved in the JSON state `file` instead of starting from a pattern")
+	flag.StringVar(&cfg.output, "output", "gnuplot", "output format: "+strings.Join(outputNames, ", "))
+	var formatOpt *string = flag.String("format", "", "with json, read the starting world from stdin and write every generation to stdout as JSON, a list of [x, y] pairs of the live cells")
+	flag.BoolVar(&cfg.interactive, "interactive", false, "watch the run in the terminal and steer it: space pauses, n steps, + and - change the speed, arrows pan, q quits")
+	flag.StringVar(&cfg.serve, "serve", "", "serve the run live to browsers on `address`, e.g. :8080, instead of writing the output")
+	flag.StringVar(&cfg.outputPath, "o", "", "write the output to `file` instead of stdout; for png a name with a %d for the generation, default "+pngFramePath+"; for svg a %d in the name writes every generation, otherwise the last one")
+	flag.IntVar(&cfg.render.Scale, "cell-size", 10, "size of a cell in `pixels`, for image outputs")
+	flag.DurationVar(&cfg.frameDelay, "frame-delay", 100*time.Millisecond, "time between two generations of an animation")
+	flag.IntVar(&cfg.interpolate, "interpolate", 1, "show every generation of an animation in `n` frames, fading births in and deaths out")
+	flag.IntVar(&cfg.render.Grid, "grid", 0, "draw grid lines every `n` cells (0 disables the grid)")
+	flag.BoolVar(&cfg.render.Axis, "axis", true, "draw the axis border and ticks")
+	flag.BoolVar(&cfg.render.Origin, "origin", false, "mark the origin of the world")
+	var background *string = flag.String("background", "", "background `color` of the plot, e.g. #ffffff, overriding the theme")
+	var cellColor *string = flag.String("cell-color", "", "`color` of the live cells, e.g. #0060ad, overriding the theme")
+	var themeOpt *string = flag.String("theme", "classic", "color theme: "+strings.Join(render.ThemeNames(), ", ")+" or one from the theme file")
+	var themeFile *string = flag.String("theme-file", "", "`file` with additional theme definitions")
+	var shapeOpt *string = flag.String("cell-shape", "point", "shape of a live cell: "+strings.Join(render.CellShapeNames(), ", ")+"; a point is a circle drawn with the points of gnuplot in the gnuplot output")
+	flag.IntVar(&cfg.render.Gap, "cell-gap", 0, "empty `pixels` between neighbouring cells")
+	flag.BoolVar(&cfg.render.Ages, "color-by-age", false, "color the live cells from the origin color when they are born to the cell color as they age, in the gnuplot, image and terminal outputs")
+	flag.StringVar(&cfg.exportMtx, "export-mtx", "", "write the last generation as a Matrix Market sparse matrix to `file`, or as a SciPy sparse matrix if it ends in .npz; a %d in the name writes every generation")
+	flag.StringVar(&cfg.exportCells, "export-cells", "", "write the last generation as a plaintext .cells pattern to `file`, or as a Golly macrocell if it ends in .mc; a %d in the name writes every generation")
+	flag.StringVar(&cfg.exportCSV, "export-csv", "", "write gen,x,y rows for the live cells of every generation to a CSV `file`")
+	flag.StringVar(&cfg.exportParquet, "export-parquet", "", "write (gen, x, y) rows for the live cells of every generation to a Parquet `file`")
+	flag.StringVar(&cfg.exportASCII, "export-asciinema", "", "write every generation as a text frame to `file`, as an asciinema recording if it ends in .cast")
+	flag.StringVar(&cfg.stats, "stats", "", "write the population, births, deaths, bounding box and density of every generation to a CSV `file`, or to stderr for -")
+	var matchOpt *string = flag.String("match", "", "report where the pattern in `file` appears in the world, in any orientation, as gen,x,y,orientation rows of CSV")
+	flag.StringVar(&cfg.matchCSV, "match-csv", "-", "write the rows of -match to `file`, or to stderr for -")
+	flag.BoolVar(&cfg.matchExact, "match-exact", false, "only report occurrences of -match standing on their own, with dead cells around them")
+	flag.BoolVar(&cfg.index, "index", false, "write an index.html linking and previewing all files written by the run into the directory of the output")
+	flag.BoolVar(&cfg.dryRun, "dry-run", false, "print what would be done and exit")
+	flag.BoolVar(&cfg.estimate, "estimate", false, "estimate memory and running time from a short calibration run before starting")
+	flag.BoolVar(&cfg.phaseTimings, "phase-timings", false, "report the time spent in each phase of a tick on stderr")
+	flag.BoolVar(&cfg.debugAllocs, "debug-allocs", false, "report the heap allocations made computing the generations on stderr")
+	flag.StringVar(&cfg.trace, "trace", "", "write a runtime trace of the run to `file`, for go tool trace")
+	flag.BoolVar(&cfg.verifyRules, "verify-rules", false, "check the engine against the rule for all 512 neighbourhoods and exit")
+	var pruneOpt *string = flag.String("prune", "always", "when to drop dead cells: always, every=K generations, or halo to keep the dead cells next to live ones")
+	flag.BoolVar(&cfg.dropFrames, "drop-frames", false, "skip rendering generations while the output is not keeping up, instead of waiting for it")
+	flag.Float64Var(&cfg.maxGPS, "max-gps", 0, "limit the simulation to `n` generations per second, 0 for no limit")
+	var driftOpt *string = flag.String("drift", "", "move the view along with a spaceship by `dx,dy/period`, e.g. 1,-1/4 for the glider of -pattern glider")
+	flag.IntVar(&cfg.engine.Workers, "workers", cntWorkers, "number of goroutines computing a generation of a large world, and generating the random soup")
+	var ruleOpt *string = flag.String("rule", "", "life-like `rule` in B/S notation, e.g. B36/S23 for HighLife, or Generations rule in S/B/C notation, e.g. 345/2/4 for Star Wars; defaults to the rule of the pattern file, or B3/S23")
+	var topology *string = flag.String("topology", "plane", "shape of the world: plane, which is unbounded, or torus, which wraps around at -width and -height")
+	var width *int = flag.Int("width", 0, "width of the torus in `cells`, 0 for -size")
+	var height *int = flag.Int("height", 0, "height of the torus in `cells`, 0 for -size")
+	flag.IntVar(&cfg.wrapMargin, "wrap-margin", 0, "on a torus, warn when the live cells come within `n` cells of wrapping around into themselves, where the run stops behaving like the infinite plane; 0 for no warning")
+	flag.BoolVar(&cfg.strictTopology, "strict-topology", false, "stop the run with an error instead of warning with -wrap-margin")
+	var regionOpt *string = flag.String("region", "", "only simulate the cells within `x0,y0:x1,y1`, or the visible window with view; everything outside stays dead")
+	flag.Var(&cfg.alerts, "alert", "act once `metric op value:action` holds, e.g. pop>100000:stop; metric is pop, width, height or gen, action is stop, dump[=file], exec=command or notify; repeatable")
+	flag.Var(&cfg.detectors, "detector", "add a column to the -stats rows counting the events in a region, `name=x0,y0:x1,y1[:file]`: the cells born in it, or the occurrences of the pattern in file appearing in it, as it is in the file; repeatable")
+	flag.StringVar(&cfg.webhook, "webhook", "", "post a JSON event to `url` when the run completes or fails, and for notify alerts; errors in the command line are only printed")
+	flag.IntVar(&cfg.render.Bin, "zoom-out", 1, "draw `n` x n cells as one dot shaded by the number of live cells")
+	flag.Parse()
+
+	if *driftOpt != "" {
+		d, err := parseDrift(*driftOpt)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		cfg.drift = d
+	}
+
+	if cfg.step < 1 {
+		fmt.Printf("invalid step %d\n", cfg.step)
+		os.Exit(1)
+	}
+
+	if cfg.density < 0 || cfg.density > 1 {
+		fmt.Printf("invalid density %g, expected a probability from 0 to 1\n", cfg.density)
+		os.Exit(1)
+	}
+
+	if !slices.Contains(rngNames, cfg.rng) {
+		fmt.Printf("unknown random number generator %q\n", cfg.rng)
+		os.Exit(1)
+	}
+
+	if cfg.engine.Workers < 1 {
+		fmt.Printf("invalid number of workers %d\n", cfg.engine.Workers)
+		os.Exit(1)
+	}
+
+	switch *topology {
+	case "plane":
+	case "torus":
+		t := engine.Torus{Width: *width, Height: *height}
+		if t.Width == 0 {
+			t.Width = cfg.size
+		}
+		if t.Height == 0 {
+			t.Height = cfg.size
+		}
+		if t.Width < 1 || t.Height < 1 {
+			fmt.Printf("invalid torus size %dx%d\n", t.Width, t.Height)
+			os.Exit(1)
+		}
+		cfg.engine.Torus = &t
+	default:
+		fmt.Printf("unknown topology %q\n", *topology)
+		os.Exit(1)
+	}
+	if (cfg.wrapMargin != 0 || cfg.strictTopology) && cfg.engine.Torus == nil {
+		fmt.Println("-wrap-margin and -strict-topology need -topology torus")
+		os.Exit(1)
+	}
+	if cfg.wrapMargin < 0 || cfg.strictTopology && cfg.wrapMargin == 0 {
+		fmt.Printf("invalid wrap margin %d, -strict-topology needs a margin of at least 1\n", cfg.wrapMargin)
+		os.Exit(1)
+	}
+
+	switch *engineOpt {
+	case "map":
+	case "hashlife":
+		// Hashlife works on the whole unbounded plane at once
+		if cfg.engine.Torus != nil || *regionOpt != "" || cfg.phaseTimings || cfg.detectCycle > 0 || cfg.render.Ages {
+			fmt.Println("-engine hashlife cannot be combined with -topology torus, -region, -phase-timings, -detect-cycle or -color-by-age")
+			os.Exit(1)
+		}
+		cfg.hashlife = true
+	case "incremental":
+		// The neighbour counts are kept from one generation to the next,
+		// clipping and timing the phases of a tick do not fit in
+		if *regionOpt != "" || cfg.phaseTimings {
+			fmt.Println("-engine incremental cannot be combined with -region or -phase-timings")
+			os.Exit(1)
+		}
+		cfg.incremental = true
+	case "dense":
+		// The bitboard needs bounds, and has no room for ages
+		if cfg.engine.Torus == nil || *regionOpt != "" || cfg.phaseTimings || cfg.render.Ages {
+			fmt.Println("-engine dense needs -topology torus, and cannot be combined with -region, -phase-timings or -color-by-age")
+			os.Exit(1)
+		}
+		cfg.dense = true
+	default:
+		fmt.Printf("unknown engine %q\n", *engineOpt)
+		os.Exit(1)
+	}
+
+	switch *regionOpt {
+	case "":
+	case "view":
+		h := cfg.size / 2
+		cfg.region = &engine.Region{Min: engine.Coord{X: -h, Y: -h}, Max: engine.Coord{X: h, Y: h}}
+	default:
+		r, err := engine.ParseRegion(*regionOpt)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		cfg.region = &r
+	}
+
+	if cfg.serve != "" && cfg.interactive {
+		fmt.Println("-serve cannot be combined with -interactive")
+		os.Exit(1)
+	}
+	if cfg.interactive {
+		if cfg.dropFrames {
+			fmt.Println("-interactive cannot be combined with -drop-frames")
+			os.Exit(1)
+		}
+		cfg.output = "term"
+	}
+	switch *formatOpt {
+	case "":
+	case "json":
+		// The world comes in on stdin and goes out on stdout
+		var conflicts []string
+		flag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "output", "o", "interactive", "serve", "random", "ash", "resume", "file", "pattern", "coordinates":
+				conflicts = append(conflicts, "-"+f.Name)
+			}
+		})
+		if len(conflicts) > 0 {
+			fmt.Printf("-format json cannot be combined with %s\n", strings.Join(conflicts, ", "))
+			os.Exit(1)
+		}
+		cfg.output = "json"
+	default:
+		fmt.Printf("unknown format %q\n", *formatOpt)
+		os.Exit(1)
+	}
+	if !slices.Contains(outputNames, cfg.output) {
+		fmt.Printf("unknown output format %q\n", cfg.output)
+		os.Exit(1)
+	}
+	if cfg.output == "png" && cfg.outputPath != "" && cfg.serve == "" {
+		if err := render.CheckFramePath(cfg.outputPath); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	for _, a := range cfg.alerts {
+		if a.action == "notify" && cfg.webhook == "" {
+			fmt.Printf("alert %s needs a -webhook to notify\n", a.spec)
+			os.Exit(1)
+		}
+	}
+
+	if len(cfg.detectors) > 0 && cfg.stats == "" {
+		fmt.Println("-detector needs -stats to write its counts to")
+		os.Exit(1)
+	}
+	for i := range cfg.detectors {
+		if err := cfg.detectors[i].load(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if *matchOpt != "" {
+		p, err := pattern.Load(*matchOpt)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if len(p.Cells) == 0 {
+			fmt.Printf("%s: nothing to match, the pattern has no live cells\n", *matchOpt)
+			os.Exit(1)
+		}
+		cfg.match = p
+	}
+
+	prune, err := engine.ParsePrunePolicy(*pruneOpt)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	cfg.prune = prune
+
+	shape, err := render.ParseCellShape(*shapeOpt)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	cfg.render.Shape = shape
+
+	if *themeFile != "" {
+		if err := render.LoadThemes(*themeFile); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	t, found := render.Themes[*themeOpt]
+	if !found {
+		fmt.Printf("unknown theme %q\n", *themeOpt)
+		os.Exit(1)
+	}
+	if *background != "" {
+		if !render.IsColor(*background) {
+			fmt.Printf("%q is not a #rrggbb color\n", *background)
+			os.Exit(1)
+		}
+		t.Background = *background
+	}
+	if *cellColor != "" {
+		if !render.IsColor(*cellColor) {
+			fmt.Printf("%q is not a #rrggbb color\n", *cellColor)
+			os.Exit(1)
+		}
+		t.Cell = *cellColor
+	}
+	cfg.render.Theme = t
+
+	size := cfg.size
+
+	// Create a ranodm starting pattern or use the r-pentomino pattern
+	if cfg.ash {
+		cfg.random = true
+	}
+
+	if cfg.resume != "" {
+		if cfg.random {
+			fmt.Println("-resume cannot be combined with -random or -ash")
+			os.Exit(1)
+		}
+		s, err := loadState(cfg.resume)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		cfg.pattern = s.pattern()
+		if cfg.pattern.Name == "" {
+			cfg.pattern.Name = cfg.resume
+		}
+		cfg.seed = s.Seed
+		if s.RNG != "" {
+			cfg.rng = s.RNG
+		}
+		cfg.start = s.Generation
+		cfg.decaying = s.decaying()
+	} else if cfg.random {
+		// Generate a random pattern
+		if cfg.seed == 0 {
+			cfg.seed = engine.CryptoSeed()
+		}
+		cfg.pattern.Name = fmt.Sprintf("random %dx%d soup", size, size)
+		if cfg.density != 0.2 {
+			cfg.pattern.Name = fmt.Sprintf("random %dx%d soup of density %g", size, size, cfg.density)
+		}
+		if cfg.ash {
+			cfg.pattern.Name = fmt.Sprintf("random %dx%d ash field", size, size)
+		}
+	} else {
+		p, err := loadPattern()
+		if *formatOpt == "json" {
+			p, err = readJSONPattern(os.Stdin, "stdin")
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		cfg.pattern = p
+	}
+
+	// Without -rule the pattern says which rule it is meant for
+	cfg.engine.Rule = engine.Conway
+	if *ruleOpt != "" {
+		r, err := engine.ParseRule(*ruleOpt)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		cfg.engine.Rule = r
+	} else if cfg.pattern.Rule != "" {
+		r, err := engine.ParseRule(cfg.pattern.Rule)
+		if err != nil && cfg.strictImport {
+			fmt.Println(err)
+			os.Exit(1)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "%v, using %s instead\n", err, cfg.engine.Rule)
+		} else {
+			cfg.engine.Rule = r
+		}
+	}
+
+	// The other engines only know live and dead cells
+	if cfg.engine.Rule.States > 0 && (cfg.hashlife || cfg.incremental || cfg.dense) {
+		fmt.Printf("the Generations rule %s can only be computed with -engine map\n", cfg.engine.Rule)
+		os.Exit(1)
+	}
+	// Only the bitboard of the torus has the dead cells far from the live
+	// ones that come alive with B0
+	if cfg.engine.Rule.Birth[0] && !cfg.dense {
+		fmt.Printf("the rule %s with B0 can only be computed with -engine dense\n", cfg.engine.Rule)
+		os.Exit(1)
+	}
+	cfg.render.States = cfg.engine.Rule.States
+
+	if !cfg.random && cfg.resume == "" {
+		problems := importProblems(cfg.pattern, cfg, *ruleOpt)
+		for _, problem := range problems {
+			if cfg.strictImport {
+				fmt.Println(problem)
+			} else {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", problem)
+			}
+		}
+		if cfg.strictImport && len(problems) > 0 {
+			os.Exit(1)
+		}
+	}
+
+	return cfg
+}
+
+// rngNames are the random number generators a random pattern can be
+// generated with. math is the math/rand generator, xoshiro the faster
+// xoshiro256**.
+var rngNames = []string{"math", "xoshiro"}
+
+// newRNG returns the random number generator with the given name, seeded
+// with seed
+func newRNG(name string, seed int64) engine.RNG {
+	if name == "xoshiro" {
+		return engine.NewXoshiroRNG(seed)
+	}
+	return engine.NewMathRNG(seed)
+}
+
+// patternFlags defines the flags selecting a pattern on the flag set. The
+// returned function loads the pattern once the flags are parsed.
+func patternFlags(fs *flag.FlagSet) func() (pattern.Pattern, error) {
+	coordinates := fs.String("coordinates", "1,0;0,1;1,1;1,2;2,2", "semi-colon-separated list of coordinates")
+	file := fs.String("file", "", "read the pattern from `file` in RLE (.rle), plaintext (.cells), Life 1.05/1.06 (.lif) or Golly macrocell (.mc) format, instead of -coordinates")
+	named := fs.String("pattern", "", "use the well-known pattern with this `name` instead of -coordinates: "+strings.Join(pattern.Names(), ", "))
+	center := fs.Bool("center", false, "move the pattern so that its bounding box is centred on the origin, as a torus is, before -offset")
+	offset := fs.String("offset", "", "move the pattern by `dx,dy`")
+
+	return func() (pattern.Pattern, error) {
+		var p pattern.Pattern
+		var err error
+		switch {
+		case *file != "":
+			p, err = pattern.Load(*file)
+		case *named != "":
+			p, err = pattern.Named(*named)
+		default:
+			p, err = pattern.ParseCoordinates(*coordinates)
+		}
+		if err != nil {
+			return p, err
+		}
+		if *center {
+			p = centered(p)
+		}
+		if *offset == "" {
+			return p, nil
+		}
+		d, err := parseCoordPair(*offset)
+		if err != nil {
+			return p, err
+		}
+		return p.Translate(d), nil
+	}
+}
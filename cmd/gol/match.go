@@ -0,0 +1,77 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// matchExporter writes a CSV row for every occurrence of a query pattern
+// appearing in the generations of a run: the generation, the lower left
+// corner of the occurrence and its orientation. With -step only every
+// step-th generation is searched.
+type matchExporter struct {
+	c     io.Closer // nil for stderr
+	w     *csv.Writer
+	m     *pattern.Matcher
+	query string
+	found int
+}
+
+func newMatchExporter(path string, query pattern.Pattern, exact bool, world engine.World, gen int) (*matchExporter, error) {
+	e := &matchExporter{m: pattern.NewMatcher(query, exact), query: query.Name}
+	if path == "-" {
+		e.w = csv.NewWriter(os.Stderr)
+	} else {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		e.c, e.w = f, csv.NewWriter(f)
+	}
+	e.w.Write([]string{"gen", "x", "y", "orientation"})
+	return e, e.export(world, gen)
+}
+
+func (e *matchExporter) export(world engine.World, gen int) error {
+	found := e.m.Next(world)
+	// In a fixed order, not the one of the maps
+	slices.SortFunc(found, func(a, b pattern.Match) int {
+		if a.At.Y != b.At.Y {
+			return cmp.Compare(a.At.Y, b.At.Y)
+		}
+		if a.At.X != b.At.X {
+			return cmp.Compare(a.At.X, b.At.X)
+		}
+		return cmp.Compare(a.Orientation, b.Orientation)
+	})
+	for _, m := range found {
+		e.w.Write([]string{strconv.Itoa(gen), strconv.Itoa(m.At.X), strconv.Itoa(m.At.Y), m.Orientation})
+	}
+	e.found += len(found)
+	if e.c == nil {
+		// Flush every generation, so stderr can be watched while the run
+		// goes on
+		e.w.Flush()
+	}
+	return e.w.Error()
+}
+
+func (e *matchExporter) close() error {
+	e.w.Flush()
+	err := e.w.Error()
+	if e.c != nil {
+		if cerr := e.c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s appeared %d times\n", e.query, e.found)
+	return err
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"os"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// parquetExporter writes every live cell of every generation as a
+// (gen, x, y) row to an Apache Parquet file. All three columns are
+// required INT32 columns with plain encoding and no compression, which
+// every Parquet reader understands. Rows are buffered and written in row
+// groups, so runs of any length can be exported.
+type parquetExporter struct {
+	f      *os.File
+	w      *bufio.Writer
+	offset int64
+
+	gen, x, y []int32
+	rowGroups []parquetRowGroup
+	numRows   int64
+}
+
+// parquetRowGroupRows is the number of rows buffered before a row group
+// is written
+const parquetRowGroupRows = 1 << 20
+
+var parquetColumns = []string{"gen", "x", "y"}
+
+// parquetColumnChunk describes a column chunk already written to the file
+type parquetColumnChunk struct {
+	offset int64 // offset of the page header
+	size   int64 // size of page header and data
+	values int64
+}
+
+type parquetRowGroup struct {
+	columns []parquetColumnChunk
+	rows    int64
+}
+
+func newParquetExporter(path string) (*parquetExporter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &parquetExporter{f: f, w: bufio.NewWriter(f)}
+	e.write([]byte("PAR1"))
+	return e, nil
+}
+
+func (e *parquetExporter) write(b []byte) {
+	e.w.Write(b)
+	e.offset += int64(len(b))
+}
+
+func (e *parquetExporter) export(world engine.World, gen int) error {
+	for _, c := range world.LiveCells() {
+		e.gen = append(e.gen, int32(gen))
+		e.x = append(e.x, int32(c.X))
+		e.y = append(e.y, int32(c.Y))
+	}
+	if len(e.gen) >= parquetRowGroupRows {
+		e.flushRowGroup()
+	}
+	return nil
+}
+
+// flushRowGroup writes the buffered rows as one row group with a single
+// data page per column
+func (e *parquetExporter) flushRowGroup() {
+	if len(e.gen) == 0 {
+		return
+	}
+
+	rg := parquetRowGroup{rows: int64(len(e.gen))}
+	for _, values := range [][]int32{e.gen, e.x, e.y} {
+		var data bytes.Buffer
+		binary.Write(&data, binary.LittleEndian, values)
+
+		var header thriftWriter
+		header.fieldI32(1, 0) // type: DATA_PAGE
+		header.fieldI32(2, int32(data.Len()))
+		header.fieldI32(3, int32(data.Len()))
+		header.fieldStruct(5) // data_page_header
+		header.fieldI32(1, int32(len(values)))
+		header.fieldI32(2, 0) // encoding: PLAIN
+		header.fieldI32(3, 3) // definition_level_encoding: RLE
+		header.fieldI32(4, 3) // repetition_level_encoding: RLE
+		header.stop()
+		header.stop()
+
+		chunk := parquetColumnChunk{offset: e.offset, values: int64(len(values))}
+		e.write(header.buf.Bytes())
+		e.write(data.Bytes())
+		chunk.size = e.offset - chunk.offset
+		rg.columns = append(rg.columns, chunk)
+	}
+
+	e.rowGroups = append(e.rowGroups, rg)
+	e.numRows += rg.rows
+	e.gen, e.x, e.y = e.gen[:0], e.x[:0], e.y[:0]
+}
+
+func (e *parquetExporter) close() error {
+	e.flushRowGroup()
+
+	var meta thriftWriter
+	meta.fieldI32(1, 1) // version
+
+	meta.fieldList(2, thriftStruct, len(parquetColumns)+1) // schema
+	meta.fieldString(4, "schema")
+	meta.fieldI32(5, int32(len(parquetColumns)))
+	meta.stop()
+	for _, name := range parquetColumns {
+		meta.fieldI32(1, 1) // type: INT32
+		meta.fieldI32(3, 0) // repetition_type: REQUIRED
+		meta.fieldString(4, name)
+		meta.stop()
+	}
+
+	meta.fieldI64(3, e.numRows)
+
+	meta.fieldList(4, thriftStruct, len(e.rowGroups)) // row_groups
+	for _, rg := range e.rowGroups {
+		var total int64
+		meta.fieldList(1, thriftStruct, len(rg.columns)) // columns
+		for i, chunk := range rg.columns {
+			meta.fieldI64(2, chunk.offset) // file_offset
+			meta.fieldStruct(3)            // meta_data
+			meta.fieldI32(1, 1)            // type: INT32
+			meta.fieldList(2, thriftI32, 2)
+			meta.i32(0) // PLAIN
+			meta.i32(3) // RLE
+			meta.fieldList(3, thriftBinary, 1)
+			meta.string(parquetColumns[i])
+			meta.fieldI32(4, 0) // codec: UNCOMPRESSED
+			meta.fieldI64(5, chunk.values)
+			meta.fieldI64(6, chunk.size)
+			meta.fieldI64(7, chunk.size)
+			meta.fieldI64(9, chunk.offset) // data_page_offset
+			meta.stop()
+			meta.stop()
+			total += chunk.size
+		}
+		meta.fieldI64(2, total)
+		meta.fieldI64(3, rg.rows)
+		meta.stop()
+	}
+
+	meta.fieldString(6, "gol")
+	meta.stop()
+
+	e.write(meta.buf.Bytes())
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(meta.buf.Len()))
+	e.write(length[:])
+	e.write([]byte("PAR1"))
+
+	if err := e.w.Flush(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
+
+// Element types of the Thrift compact protocol
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes Thrift structs in the compact protocol, which is
+// what Parquet uses for its page headers and file metadata. It supports
+// just enough of the protocol for the Parquet exporter; nested structs
+// are started with fieldStruct and ended with stop.
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16 // id of the last field written, per nesting level
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	t.buf.Write(b[:n])
+}
+
+func (t *thriftWriter) i32(v int32) {
+	t.varint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (t *thriftWriter) i64(v int64) {
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) string(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if len(t.last) == 0 {
+		t.last = append(t.last, 0)
+	}
+	last := &t.last[len(t.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.i32(int32(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) fieldI32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.i32(v)
+}
+
+func (t *thriftWriter) fieldI64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.i64(v)
+}
+
+func (t *thriftWriter) fieldString(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.string(s)
+}
+
+// fieldStruct starts a nested struct field
+func (t *thriftWriter) fieldStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.last = append(t.last, 0)
+}
+
+// fieldList starts a list field with n elements of type typ. Structs in
+// the list are written as plain fields, each ended with stop.
+func (t *thriftWriter) fieldList(id int16, typ byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | typ)
+	} else {
+		t.buf.WriteByte(0xf0 | typ)
+		t.varint(uint64(n))
+	}
+	if typ == thriftStruct {
+		for i := 0; i < n; i++ {
+			// every element starts a fresh field numbering, stop pops it
+			t.last = append(t.last, 0)
+		}
+	}
+}
+
+// stop ends the current struct
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+	if len(t.last) > 0 {
+		t.last = t.last[:len(t.last)-1]
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// A perturbation is the outcome of flipping a single cell of a pattern
+type perturbation struct {
+	flip     engine.Coord
+	healed   int // generation the difference vanished, 0 if it never did
+	diverged int // generation the difference first exceeded the threshold, 0 if never
+	final    int // cells differing from the baseline after the last generation
+	pop      int // population after the last generation
+}
+
+// perturb runs the perturbed world with the engine next to the baseline
+// generations and records how the difference develops
+func perturb(e engine.Engine, baseline []engine.World, flip engine.Coord, threshold int) perturbation {
+	p := perturbation{flip: flip}
+
+	world := make(engine.World, len(baseline[0]))
+	for coord, cell := range baseline[0] {
+		world[coord] = cell
+	}
+	if world[flip].Alive {
+		delete(world, flip)
+	} else {
+		world[flip] = engine.Cell{Alive: true}
+	}
+
+	for gen := 1; gen < len(baseline); gen++ {
+		world = e.Tick(world)
+		d := engine.Hamming(world, baseline[gen])
+		if d == 0 {
+			p.healed = gen
+			break
+		}
+		if p.diverged == 0 && d > threshold {
+			p.diverged = gen
+		}
+	}
+
+	last := baseline[len(baseline)-1]
+	if p.healed == 0 {
+		p.final = engine.Hamming(world, last)
+		p.pop = len(world)
+	} else {
+		p.pop = len(last)
+	}
+	return p
+}
+
+// runPerturb implements the perturb subcommand: it runs a pattern and a
+// number of copies with a single cell flipped, and reports for every copy
+// whether and when it healed or diverged from the baseline
+func runPerturb(args []string) error {
+	fs := flag.NewFlagSet("perturb", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: gol perturb [flags]\n\nMeasures how fragile a pattern is against single cell flips.\n\n")
+		fs.PrintDefaults()
+	}
+	loadPattern := patternFlags(fs)
+	ticks := fs.Int("ticks", 200, "number of generations to run every copy")
+	n := fs.Int("n", 10, "number of perturbed copies")
+	seed := fs.Int64("seed", 0, "seed for choosing the flipped cells, 0 picks one at random")
+	threshold := fs.Int("threshold", 10, "a copy has diverged once more than `n` cells differ from the baseline")
+	ruleOpt := fs.String("rule", "", "life-like `rule` in B/S notation, defaults to the rule of the pattern file, or B3/S23")
+	fs.Parse(args)
+
+	p, err := loadPattern()
+	if err != nil {
+		return err
+	}
+	if *seed == 0 {
+		*seed = engine.CryptoSeed()
+	}
+
+	rule := engine.Conway
+	if *ruleOpt == "" {
+		*ruleOpt = p.Rule
+	}
+	if *ruleOpt != "" {
+		if rule, err = engine.ParseRule(*ruleOpt); err != nil {
+			return err
+		}
+	}
+	if rule.Birth[0] {
+		return fmt.Errorf("the rule %s with B0 is not supported, it needs -engine dense", rule)
+	}
+	e := engine.Engine{Rule: rule}
+
+	baseline := []engine.World{p.World()}
+	for gen := 1; gen <= *ticks; gen++ {
+		baseline = append(baseline, e.Tick(baseline[gen-1]))
+	}
+
+	// Flip cells in the bounding box of the pattern and the ring around it,
+	// where a flip can make a difference at all
+	rng := engine.NewRand(engine.NewMathRNG(*seed))
+	min, max := p.Bounds()
+	flips := make([]engine.Coord, *n)
+	for i := range flips {
+		flips[i] = engine.Coord{X: min.X - 1 + rng.Intn(max.X-min.X+3), Y: min.Y - 1 + rng.Intn(max.Y-min.Y+3)}
+	}
+
+	results := make([]perturbation, len(flips))
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < cntWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = perturb(e, baseline, flips[i], *threshold)
+			}
+		}()
+	}
+	for i := range flips {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	fmt.Printf("# seed=%d ticks=%d rule=%s baseline population %d\n", *seed, *ticks, rule, len(baseline[*ticks]))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "flip\toutcome\tgeneration\tfinal difference\tfinal population")
+	for _, r := range results {
+		outcome, gen := "contained", ""
+		switch {
+		case r.healed > 0:
+			outcome, gen = "healed", fmt.Sprint(r.healed)
+		case r.diverged > 0:
+			outcome, gen = "diverged", fmt.Sprint(r.diverged)
+		}
+		fmt.Fprintf(tw, "%d,%d\t%s\t%s\t%d\t%d\n", r.flip.X, r.flip.Y, outcome, gen, r.final, r.pop)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// runPhase implements the phase subcommand: it detects the period of an
+// oscillator or spaceship and prints the requested phase of it
+func runPhase(args []string) error {
+	fs := flag.NewFlagSet("phase", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: gol phase [flags]\n\nDetects the period of a pattern and prints the pattern advanced to a phase.\n\n")
+		fs.PrintDefaults()
+	}
+	loadPattern := patternFlags(fs)
+	to := fs.Int("to", 0, "advance the pattern to `phase` n of its period")
+	maxPeriod := fs.Int("max-period", 1000, "give up if the pattern has not repeated after `n` generations")
+	ruleOpt := fs.String("rule", "", "life-like `rule` in B/S notation, defaults to the rule of the pattern file, or B3/S23")
+	fs.Parse(args)
+
+	p, err := loadPattern()
+	if err != nil {
+		return err
+	}
+	world := p.World()
+
+	rule := engine.Conway
+	if *ruleOpt == "" {
+		*ruleOpt = p.Rule
+	}
+	if *ruleOpt != "" {
+		if rule, err = engine.ParseRule(*ruleOpt); err != nil {
+			return err
+		}
+	}
+	if rule.Birth[0] {
+		return fmt.Errorf("the rule %s with B0 is not supported, it needs -engine dense", rule)
+	}
+	e := engine.Engine{Rule: rule}
+
+	period, shift, ok := e.DetectPeriod(world, *maxPeriod)
+	if !ok {
+		return fmt.Errorf("pattern does not repeat within %d generations", *maxPeriod)
+	}
+	if shift == (engine.Coord{}) {
+		fmt.Fprintf(os.Stderr, "period %d\n", period)
+	} else {
+		fmt.Fprintf(os.Stderr, "period %d, moving by %d,%d per period\n", period, shift.X, shift.Y)
+	}
+
+	for i := 0; i < (*to%period+period)%period; i++ {
+		world = e.Tick(world)
+	}
+	fmt.Println(pattern.FormatCoordinates(pattern.Pattern{Cells: world.LiveCells()}))
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// printPlan describes what a run with the given configuration would do to
+// the initial world, without doing any of it
+func printPlan(w io.Writer, cfg config, world engine.World) {
+	fmt.Fprintln(w, "Execution plan")
+	coords := world.LiveCells()
+	fmt.Fprintf(w, "  pattern:     %s, %d live cells", cfg.pattern.Name, len(coords))
+	if cfg.random {
+		fmt.Fprintf(w, ", seed %d", cfg.seed)
+	}
+	fmt.Fprintln(w)
+	if len(coords) > 0 {
+		min, max := engine.Bounds(coords)
+		fmt.Fprintf(w, "  bounds:      (%d,%d) to (%d,%d)\n", min.X, min.Y, max.X, max.Y)
+	}
+	if cfg.resume != "" {
+		fmt.Fprintf(w, "  resumes:     %s at generation %d\n", cfg.resume, cfg.start)
+	}
+	fmt.Fprintf(w, "  generations: %d", cfg.ticks*cfg.step)
+	if cfg.step > 1 {
+		fmt.Fprintf(w, ", %d frames %d generations apart", cfg.ticks, cfg.step)
+	}
+	if cfg.maxGPS > 0 {
+		fmt.Fprintf(w, ", at most %g per second", cfg.maxGPS)
+	}
+	if cfg.detectCycle > 0 {
+		fmt.Fprintf(w, ", stopping early at a cycle of up to %d generations", cfg.detectCycle)
+	}
+	fmt.Fprintln(w)
+	if len(cfg.alerts) > 0 {
+		fmt.Fprintf(w, "  alerts:      %s\n", &cfg.alerts)
+	}
+	for _, d := range cfg.detectors {
+		fmt.Fprintf(w, "  detector:    %s, %s\n", d.name, d.what())
+	}
+	if cfg.webhook != "" {
+		fmt.Fprintf(w, "  webhook:     %s\n", cfg.webhook)
+	}
+	if cfg.hashlife {
+		fmt.Fprintf(w, "  engine:      hashlife, rule %s\n", cfg.engine.Rule)
+	} else if cfg.incremental {
+		fmt.Fprintf(w, "  engine:      incremental, rule %s", cfg.engine.Rule)
+		if cfg.engine.Torus != nil {
+			fmt.Fprintf(w, ", on a %s", cfg.engine.Torus)
+		}
+		fmt.Fprintln(w)
+	} else if cfg.dense {
+		fmt.Fprintf(w, "  engine:      dense, rule %s, %d workers, on a %s\n", cfg.engine.Rule, cfg.engine.Workers, cfg.engine.Torus)
+	} else {
+		fmt.Fprintf(w, "  engine:      map, rule %s, %d workers, pruning dead cells %s", cfg.engine.Rule, cfg.engine.Workers, cfg.prune)
+		if cfg.engine.Torus != nil {
+			fmt.Fprintf(w, ", on a %s", cfg.engine.Torus)
+		}
+		if cfg.region != nil {
+			fmt.Fprintf(w, ", clipped to %s", cfg.region)
+		}
+		fmt.Fprintln(w)
+	}
+
+	r := cfg.render
+	dest := "stdout"
+	if cfg.outputPath != "" {
+		dest = cfg.outputPath
+	}
+	switch {
+	case cfg.serve != "":
+		fmt.Fprintf(w, "  output:      served live on %s, %dx%d view, %s per generation, theme %s", cfg.serve, cfg.size, cfg.size, cfg.frameDelay, r.Theme.Name)
+	case cfg.output == "gif":
+		n := (2*(cfg.size/2) + 1) * r.Scale
+		fmt.Fprintf(w, "  output:      animated GIF to %s, %dx%d pixels, %s per generation", dest, n, n, cfg.frameDelay)
+		if cfg.interpolate > 1 {
+			fmt.Fprintf(w, " in %d frames", cfg.interpolate)
+		}
+		fmt.Fprintf(w, ", theme %s", r.Theme.Name)
+	case cfg.output == "png":
+		if cfg.outputPath == "" {
+			dest = pngFramePath
+		}
+		n := (2*(cfg.size/2) + 1) * r.Scale
+		fmt.Fprintf(w, "  output:      PNG frames to %s, %dx%d pixels, theme %s", dest, n, n, r.Theme.Name)
+	case cfg.output == "svg":
+		what := "the last generation"
+		if strings.Contains(dest, "%") {
+			what = "every generation"
+		}
+		n := (2*(cfg.size/2) + 1) * r.Scale
+		fmt.Fprintf(w, "  output:      %s as SVG to %s, %dx%d units, theme %s", what, dest, n, n, r.Theme.Name)
+	case cfg.output == "term":
+		if cfg.interactive {
+			fmt.Fprintf(w, "  output:      interactive terminal animation, %dx%d view, %s per generation, theme %s", cfg.size, cfg.size, cfg.frameDelay, r.Theme.Name)
+		} else {
+			fmt.Fprintf(w, "  output:      terminal animation on %s, %dx%d view, %s per generation, theme %s", dest, cfg.size, cfg.size, cfg.frameDelay, r.Theme.Name)
+		}
+	case cfg.output == "json":
+		fmt.Fprintf(w, "  output:      every generation as a line of JSON to %s", dest)
+	default:
+		fmt.Fprintf(w, "  output:      gnuplot script to %s, %dx%d view, theme %s", dest, cfg.size, cfg.size, r.Theme.Name)
+	}
+	if cfg.serve != "" {
+		// The page draws every cell as a square
+	} else if cfg.output == "json" {
+		// Nothing is drawn
+	} else if r.Bin > 1 {
+		fmt.Fprintf(w, ", zoomed out %dx%d cells per dot", r.Bin, r.Bin)
+	} else {
+		fmt.Fprintf(w, ", %s cells", r.Shape)
+	}
+	fmt.Fprintln(w)
+
+	files := []struct{ path, what string }{
+		{cfg.exportMtx, "sparse matrix"},
+		{cfg.exportCells, "plaintext pattern"},
+		{cfg.exportCSV, "CSV cell list"},
+		{cfg.exportParquet, "Parquet cell list"},
+		{cfg.exportASCII, "text frames"},
+		{cfg.save, "state to resume from"},
+		{cfg.stats, "CSV statistics"},
+	}
+	if len(cfg.match.Cells) > 0 {
+		files = append(files, struct{ path, what string }{cfg.matchCSV, "occurrences of " + cfg.match.Name})
+	}
+	for _, f := range files {
+		if f.path != "" {
+			fmt.Fprintf(w, "  writes:      %s (%s)\n", f.path, f.what)
+		}
+	}
+	if cfg.index {
+		fmt.Fprintf(w, "  writes:      %s (index of the files)\n", filepath.Join(indexDir(cfg), "index.html"))
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/render"
+)
+
+// pngFramePath is where the PNG frames go when no -o is given
+const pngFramePath = "frame_%04d.png"
+
+// outputNames are the formats the generations can be rendered in
+var outputNames = []string{"gnuplot", "gif", "png", "svg", "term", "json"}
+
+// newRenderer creates the renderer for the configured output format,
+// writing to the configured output file or stdout
+func newRenderer(cfg config, meta render.Metadata) (render.Renderer, error) {
+	if cfg.output == "png" {
+		path := cfg.outputPath
+		if path == "" {
+			path = pngFramePath
+		}
+		return render.NewPNG(path, cfg.size, cfg.render), nil
+	}
+	if cfg.output == "svg" && strings.Contains(cfg.outputPath, "%") {
+		return render.NewSVG(cfg.outputPath, nil, cfg.size, cfg.render, meta), nil
+	}
+
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if cfg.outputPath != "" {
+		var err error
+		if f, err = os.Create(cfg.outputPath); err != nil {
+			return nil, err
+		}
+		w = f
+	}
+
+	var r render.Renderer
+	switch cfg.output {
+	case "gif":
+		r = render.NewGIF(w, cfg.size, cfg.render, cfg.frameDelay, cfg.interpolate)
+	case "svg":
+		r = render.NewSVG(cfg.outputPath, w, cfg.size, cfg.render, meta)
+	case "json":
+		r = newJSONRenderer(w)
+	case "term":
+		r = newTermRenderer(w, cfg.size, cfg.render, cfg.frameDelay)
+	default:
+		r = render.NewGnuplot(w, cfg.size, cfg.render, meta)
+	}
+	if f != nil {
+		r = fileRenderer{r, f}
+	}
+	return r, nil
+}
+
+// fileRenderer closes the file a renderer writes to after the renderer
+type fileRenderer struct {
+	render.Renderer
+	f *os.File
+}
+
+func (r fileRenderer) Close() error {
+	if err := r.Renderer.Close(); err != nil {
+		r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
+
+// runMetadata collects the metadata of a run from its configuration and
+// initial world
+func runMetadata(cfg config, world engine.World) render.Metadata {
+	var m render.Metadata
+	m.Add("rule", cfg.engine.Rule.String())
+	m.Add("pattern", cfg.pattern.Name)
+	if cfg.random {
+		m.Add("seed", strconv.FormatInt(cfg.seed, 10))
+		if cfg.rng != "math" {
+			m.Add("rng", cfg.rng)
+		}
+	}
+	m.Add("cells", strconv.Itoa(len(world)))
+	m.Add("ticks", strconv.Itoa(cfg.ticks))
+	m.Add("size", strconv.Itoa(cfg.size))
+	m.Add("prune", cfg.prune.String())
+	return m
+}
+
+// droppingRenderer hands generations to another renderer in the
+// background. When that renderer is still busy with an earlier generation
+// the new one is dropped, so a slow consumer of the output does not stall
+// the simulation.
+type droppingRenderer struct {
+	frames  chan frame
+	done    chan error
+	last    *frame // the latest generation, if it was dropped
+	dropped int
+	total   int
+}
+
+type frame struct {
+	world engine.World
+	gen   int
+}
+
+// pendingFrames is the number of generations that may wait for a busy
+// renderer before frames are dropped
+const pendingFrames = 2
+
+func newDroppingRenderer(r render.Renderer) *droppingRenderer {
+	d := &droppingRenderer{
+		frames: make(chan frame, pendingFrames),
+		done:   make(chan error, 1),
+	}
+
+	go func() {
+		var err error
+		for f := range d.frames {
+			if err == nil {
+				err = r.Render(f.world, f.gen)
+			}
+		}
+		if cerr := r.Close(); err == nil {
+			err = cerr
+		}
+		d.done <- err
+	}()
+
+	return d
+}
+
+func (d *droppingRenderer) Render(world engine.World, gen int) error {
+	d.total++
+	select {
+	case d.frames <- frame{world, gen}:
+		d.last = nil
+	default:
+		d.last = &frame{world, gen}
+		d.dropped++
+	}
+	return nil
+}
+
+// close waits for the renderer to finish. The final generation is always
+// rendered, even if it had to be dropped at first.
+func (d *droppingRenderer) Close() error {
+	if d.last != nil {
+		d.frames <- *d.last
+		d.dropped--
+	}
+	close(d.frames)
+	return <-d.done
+}
+
+// A drift is a constant velocity of dx, dy cells every period generations
+type drift struct {
+	dx, dy, period int
+}
+
+// parseDrift parses a drift written as dx,dy or dx,dy/period, e.g. 1,-1/4
+// for a glider flying to the lower right
+func parseDrift(s string) (drift, error) {
+	d := drift{period: 1}
+	v, p, found := strings.Cut(s, "/")
+	if found {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 {
+			return d, fmt.Errorf("invalid drift period in %q", s)
+		}
+		d.period = n
+	}
+	x, y, found := strings.Cut(v, ",")
+	if !found {
+		return d, fmt.Errorf("invalid drift %q, expected dx,dy/period", s)
+	}
+	var err error
+	if d.dx, err = strconv.Atoi(x); err != nil {
+		return d, fmt.Errorf("invalid drift %q: %v", s, err)
+	}
+	if d.dy, err = strconv.Atoi(y); err != nil {
+		return d, fmt.Errorf("invalid drift %q: %v", s, err)
+	}
+	return d, nil
+}
+
+// offset is how far the drift has moved after gen generations
+func (d drift) offset(gen int) engine.Coord {
+	return engine.Coord{X: engine.FloorDiv(gen*d.dx, d.period), Y: engine.FloorDiv(gen*d.dy, d.period)}
+}
+
+// driftRenderer moves every generation back by a drift before handing it
+// to another renderer, so a spaceship moving with the drift stays in place
+// and only its phases are seen
+type driftRenderer struct {
+	render.Renderer
+	drift drift
+}
+
+func (r driftRenderer) Render(world engine.World, gen int) error {
+	off := r.drift.offset(gen)
+	shifted := make(engine.World, len(world))
+	for coord, cell := range world {
+		shifted[engine.Coord{X: coord.X - off.X, Y: coord.Y - off.Y}] = cell
+	}
+	return r.Renderer.Render(shifted, gen)
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/trace"
+	"strconv"
+	"time"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/render"
+)
+
+// run evolves the world for the configured number of generations, feeding
+// every generation to the renderer and the exporters. It is the only way
+// out of a run once the command line is checked, so every error from then
+// on, the trace and the outputs failing to start included, reaches the
+// webhook.
+func run(cfg config, world engine.World) (err error) {
+	gen := cfg.start
+	if cfg.webhook != "" {
+		defer func() {
+			e := newWebhookEvent(cfg, "completed", statsOf(world, gen))
+			if err != nil {
+				e.Event, e.Error = "failed", err.Error()
+			}
+			if werr := postWebhook(cfg.webhook, e); werr != nil && err == nil {
+				err = werr
+			}
+		}()
+	}
+
+	if cfg.trace != "" {
+		stopTrace, err := startTrace(cfg.trace)
+		if err != nil {
+			return err
+		}
+		defer stopTrace()
+	}
+	ctx, task := trace.NewTask(context.Background(), "run")
+	defer task.End()
+
+	meta := runMetadata(cfg, world)
+	exporters, err := newExporters(cfg, world)
+	if err != nil {
+		return err
+	}
+
+	var r render.Renderer
+	var ctl *controller
+	if cfg.interactive {
+		term := newTermRenderer(os.Stdout, cfg.size, cfg.render, cfg.frameDelay)
+		if ctl, err = newController(term); err != nil {
+			return err
+		}
+		defer ctl.close()
+		r = term
+	} else if cfg.serve != "" {
+		if r, err = newServeRenderer(cfg.serve, cfg.size, cfg.render, cfg.frameDelay); err != nil {
+			return err
+		}
+	} else if r, err = newRenderer(cfg, meta); err != nil {
+		return err
+	}
+	if cfg.drift != (drift{}) {
+		r = driftRenderer{r, cfg.drift}
+	}
+	var dropping *droppingRenderer
+	if cfg.dropFrames {
+		dropping = newDroppingRenderer(r)
+		r = dropping
+	}
+
+	var allocs allocCounter
+
+	// The governor limits the generations per second, if asked to
+	var governor <-chan time.Time
+	if cfg.maxGPS > 0 {
+		t := time.NewTicker(governorInterval(cfg.step, cfg.maxGPS))
+		defer t.Stop()
+		governor = t.C
+	}
+
+	// A pattern too large for the torus wraps into itself from the start
+	wrapped := false
+	if cfg.wrapMargin > 0 {
+		if wrapped, err = checkWrap(cfg, world, gen); err != nil {
+			return err
+		}
+	}
+
+	sim := newSimulation(cfg, world, gen)
+	period := 0
+
+	// The generations emitted, for the index of the files written
+	var gens []int
+
+	for i := 0; i < cfg.ticks && period == 0; i++ {
+		gen = cfg.start + (i+1)*cfg.step
+		if governor != nil {
+			<-governor
+		}
+		trace.Log(ctx, "generation", strconv.Itoa(gen))
+
+		if cfg.debugAllocs {
+			allocs.start(cfg.step)
+		}
+		trace.WithRegion(ctx, "tick", func() {
+			gen, period = sim.advance(gen)
+		})
+		world = sim.world
+		if cfg.debugAllocs {
+			allocs.stop()
+		}
+		if cfg.wrapMargin > 0 && !wrapped {
+			if wrapped, err = checkWrap(cfg, world, gen); err != nil {
+				return err
+			}
+		}
+
+		gens = append(gens, gen)
+		region := trace.StartRegion(ctx, "render")
+		err := r.Render(world, gen)
+		region.End()
+		if err != nil {
+			return err
+		}
+
+		region = trace.StartRegion(ctx, "export")
+		for _, e := range exporters {
+			if err = e.export(world, gen); err != nil {
+				break
+			}
+		}
+		region.End()
+		if err != nil {
+			return err
+		}
+
+		if len(cfg.alerts) > 0 {
+			stop, err := checkAlerts(cfg, world, gen)
+			if err != nil {
+				return err
+			}
+			if stop {
+				break
+			}
+		}
+
+		if ctl != nil && ctl.between() {
+			break
+		}
+	}
+
+	if err := r.Close(); err != nil {
+		return err
+	}
+	if dropping != nil {
+		fmt.Fprintf(os.Stderr, "dropped %d of %d frames\n", dropping.dropped, dropping.total)
+	}
+
+	for _, e := range exporters {
+		if err := e.close(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.phaseTimings {
+		fmt.Fprintf(os.Stderr, "phases: %s\n", sim.timings)
+	}
+	if cfg.debugAllocs {
+		fmt.Fprintf(os.Stderr, "allocs: %s\n", &allocs)
+	}
+
+	if period > 0 {
+		fmt.Fprintf(os.Stderr, "%s at generation %d\n", describeCycle(world, period), gen-period)
+	}
+
+	if cfg.save != "" {
+		if err := saveState(cfg.save, cfg, world, gen); err != nil {
+			return err
+		}
+	}
+
+	if cfg.index {
+		if err := writeIndex(indexDir(cfg), meta, statsOf(world, gen), runArtifacts(cfg, gens)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// A simulation computes the generations of a run with the engine the
+// configuration asks for, a frame of step generations at a time
+type simulation struct {
+	cfg   config
+	world engine.World // the generation computed last
+
+	// Hashlife keeps its own quadtree of the world and hands out the live
+	// cells once per frame
+	hashlife *engine.HashLife
+	// The incremental and the dense engine keep the world in their own
+	// form between generations
+	stepper interface {
+		Step()
+		World() engine.World
+	}
+	// The cycle detector ends the run once the world repeats itself
+	cycles  *engine.CycleDetector
+	timings engine.PhaseTimings // with -phase-timings
+}
+
+// newSimulation returns the simulation of cfg starting from the world in
+// generation gen
+func newSimulation(cfg config, world engine.World, gen int) *simulation {
+	s := &simulation{cfg: cfg, world: world}
+	switch {
+	case cfg.hashlife:
+		s.hashlife = engine.NewHashLife(cfg.engine.Rule, world)
+	case cfg.incremental:
+		s.stepper = engine.NewIncremental(cfg.engine, world)
+	case cfg.dense:
+		s.stepper = engine.NewDense(cfg.engine, world)
+	}
+	if cfg.detectCycle > 0 {
+		s.cycles = engine.NewCycleDetector(cfg.detectCycle)
+		s.cycles.Observe(world, gen)
+	}
+	return s
+}
+
+// advance computes the frame ending in generation gen. If the world
+// repeats itself on the way, it stops at the generation it does so and
+// returns it with the period, otherwise gen and 0.
+func (s *simulation) advance(gen int) (int, int) {
+	cfg := s.cfg
+	if s.hashlife != nil {
+		s.hashlife.Step(int64(cfg.step))
+		s.world = s.hashlife.World()
+		return gen, 0
+	}
+	if s.stepper != nil {
+		defer func() { s.world = s.stepper.World() }()
+		for g := gen - cfg.step + 1; g <= gen; g++ {
+			s.stepper.Step()
+			if s.cycles != nil {
+				if p, ok := s.cycles.Observe(s.stepper.World(), g); ok {
+					return g, p
+				}
+			}
+		}
+		return gen, 0
+	}
+	for g := gen - cfg.step + 1; g <= gen; g++ {
+		if cfg.phaseTimings {
+			var p engine.PhaseTimings
+			s.world, p = cfg.engine.TickTimed(s.world, cfg.prune, g)
+			s.timings.Add(p)
+		} else {
+			s.world = cfg.prune.Prune(cfg.engine.Evolve(s.world), g)
+		}
+		if cfg.region != nil {
+			s.world.Clip(*cfg.region)
+		}
+		if s.cycles != nil {
+			if p, ok := s.cycles.Observe(s.world, g); ok {
+				return g, p
+			}
+		}
+	}
+	return gen, 0
+}
+
+// governorInterval returns the time between two frames of step generations
+// at maxGPS generations per second, at least a nanosecond
+func governorInterval(step int, maxGPS float64) time.Duration {
+	return max(time.Duration(float64(step)*float64(time.Second)/maxGPS), time.Nanosecond)
+}
+
+// describeCycle tells what a world that repeats itself every period
+// generations has become
+func describeCycle(world engine.World, period int) string {
+	switch {
+	case statsOf(world, 0).pop == 0:
+		return "died out"
+	case period == 1:
+		return "became a still life"
+	}
+	return fmt.Sprintf("became periodic with period %d", period)
+}
+
+// startTrace starts writing a runtime trace to the file at path. The
+// returned function stops the trace and closes the file.
+func startTrace(path string) (func(), error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := trace.Start(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		trace.Stop()
+		f.Close()
+	}, nil
+}
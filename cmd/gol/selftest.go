@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// A selfCheck is one of the checks run by gol selftest. It returns an
+// error describing what went wrong.
+type selfCheck struct {
+	name  string
+	check func() error
+}
+
+// The reference values below were recorded once and must come out the
+// same on every operating system and architecture. If one of them ever
+// has to change, a run made with an older version can no longer be
+// reproduced, so only change them together with a deliberate change of
+// the engine or the random soup generator.
+var selfChecks = []selfCheck{
+	{"r-pentomino", func() error {
+		// The R-pentomino stabilizes at generation 1103 with 116 cells
+		// when the escaping gliders are counted, too
+		p, _ := pattern.ParseCoordinates("1,0;2,0;0,1;1,1;1,2")
+		world := p.World()
+		for i := 0; i < 1103; i++ {
+			world = world.Tick()
+		}
+		if len(world) != 116 {
+			return fmt.Errorf("population %d at generation 1103, want 116", len(world))
+		}
+		return nil
+	}},
+	{"soup", func() error {
+		world := make(engine.World)
+		engine.FillRandomSoup(world, 20150101, 128, cntWorkers)
+		if n, want := len(world), 3213; n != want {
+			return fmt.Errorf("soup has %d cells, want %d", n, want)
+		}
+		if engine.NewRandomWorld(20150101, 128, 0.2).Hash() != world.Hash() {
+			return fmt.Errorf("NewRandomWorld differs from FillRandomSoup")
+		}
+		for i := 0; i < 100; i++ {
+			world = world.Tick()
+		}
+		if h, want := world.Hash(), uint64(0x81b2b54142116e12); h != want {
+			return fmt.Errorf("soup hashes to %#x after 100 generations, want %#x", h, want)
+		}
+		return nil
+	}},
+	{"soup workers", func() error {
+		// The soup must not depend on the number of workers
+		one, many := make(engine.World), make(engine.World)
+		engine.FillRandomSoup(one, 7, 300, 1)
+		engine.FillRandomSoup(many, 7, 300, 8)
+		if one.Hash() != many.Hash() {
+			return fmt.Errorf("soup differs between 1 and 8 workers")
+		}
+		return nil
+	}},
+	{"xoshiro soup", func() error {
+		one, many := make(engine.World), make(engine.World)
+		engine.FillSoup(one, engine.NewXoshiroRNG(20150101), 128, 1)
+		engine.FillSoup(many, engine.NewXoshiroRNG(20150101), 128, 8)
+		if n, want := len(one), 3302; n != want {
+			return fmt.Errorf("soup has %d cells, want %d", n, want)
+		}
+		if h, want := one.Hash(), uint64(0xcbd3e95885f16ea4); h != want {
+			return fmt.Errorf("soup hashes to %#x, want %#x", h, want)
+		}
+		if one.Hash() != many.Hash() {
+			return fmt.Errorf("soup differs between 1 and 8 workers")
+		}
+		// A generator always drawing 0 makes every cell alive
+		full := make(engine.World)
+		engine.FillSoup(full, engine.NewSequenceRNG(0), 100, 8)
+		if n := len(full); n != 100*100 {
+			return fmt.Errorf("soup of zeros has %d cells, want %d", n, 100*100)
+		}
+		return nil
+	}},
+	{"tick workers", func() error {
+		// A generation must not depend on the number of workers either.
+		// The soup is large enough for the engine to use them.
+		one, many := make(engine.World), make(engine.World)
+		engine.FillRandomSoup(one, 7, 300, cntWorkers)
+		engine.FillRandomSoup(many, 7, 300, cntWorkers)
+		serial := engine.Engine{Rule: engine.Conway, Workers: 1}
+		parallel := engine.Engine{Rule: engine.Conway, Workers: 8}
+		for i := 0; i < 20; i++ {
+			one, many = serial.Tick(one), parallel.Tick(many)
+		}
+		if one.Hash() != many.Hash() {
+			return fmt.Errorf("generation 20 of a soup differs between 1 and 8 workers")
+		}
+		for c, cell := range one {
+			if many[c].Age != cell.Age {
+				return fmt.Errorf("cell %d,%d is %d generations old with 1 worker, %d with 8", c.X, c.Y, cell.Age, many[c].Age)
+			}
+		}
+		return nil
+	}},
+	{"ash field", func() error {
+		world := make(engine.World)
+		engine.FillAshField(world, 20150101, 140, 0.5)
+		if h, want := world.Hash(), uint64(0xde4ba29f9d23cea3); h != want {
+			return fmt.Errorf("ash field hashes to %#x, want %#x", h, want)
+		}
+		return nil
+	}},
+}
+
+// runSelftest implements the selftest subcommand. It runs all self checks
+// and fails if any of them does.
+func runSelftest(args []string) error {
+	failed := 0
+	for _, c := range selfChecks {
+		if err := c.check(); err != nil {
+			fmt.Printf("FAIL %s: %v\n", c.name, err)
+			failed++
+		} else {
+			fmt.Printf("ok   %s\n", c.name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d self checks failed", failed, len(selfChecks))
+	}
+	return nil
+}
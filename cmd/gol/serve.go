@@ -0,0 +1,185 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/render"
+)
+
+// servePage is the page drawing the generations on a canvas
+//
+//go:embed serve.html
+var servePage []byte
+
+// serveRenderer serves a page showing the run live in the browser. Every
+// browser holding the page open gets the generations over a WebSocket as
+// they are computed, frames at least delay apart. A browser that cannot
+// keep up misses generations, it does not hold up the run.
+type serveRenderer struct {
+	srv   *http.Server
+	hello []byte // first message to every browser: the view and the colors
+	delay time.Duration
+	last  time.Time
+
+	mu      sync.Mutex
+	clients map[chan []byte]bool
+	latest  []byte // the generation on screen, for browsers joining late
+}
+
+// serveHello tells the page how to draw the generations
+type serveHello struct {
+	Type       string   `json:"type"`
+	Size       int      `json:"size"`
+	Background string   `json:"background"`
+	Cell       string   `json:"cell"`
+	Decay      []string `json:"decay,omitempty"` // colors of the decay states of a Generations rule
+}
+
+// serveFrame is a generation, with the live cells as x, y pairs in one
+// flat list, and the decaying cells of a Generations rule as x, y, state
+// triples
+type serveFrame struct {
+	Type       string `json:"type"`
+	Gen        int    `json:"gen"`
+	Population int    `json:"population"`
+	Cells      []int  `json:"cells"`
+	Decaying   []int  `json:"decaying,omitempty"`
+}
+
+func newServeRenderer(addr string, d int, opts render.Options, delay time.Duration) (*serveRenderer, error) {
+	var decay []string
+	for s := 1; s < opts.States-1; s++ {
+		decay = append(decay, render.ColorHex(render.DecayColor(opts.Theme, uint8(s), opts.States)))
+	}
+	hello, err := json.Marshal(serveHello{"hello", d, opts.Theme.Background, opts.Theme.Cell, decay})
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &serveRenderer{hello: hello, delay: delay, clients: make(map[chan []byte]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(servePage)
+	})
+	mux.HandleFunc("/ws", r.serveWebSocket)
+	r.srv = &http.Server{Handler: mux}
+	go r.srv.Serve(ln)
+
+	fmt.Fprintf(os.Stderr, "serving the run on http://%s/\n", ln.Addr())
+	return r, nil
+}
+
+// serveWebSocket streams the generations to one browser until it goes
+// away
+func (r *serveRenderer) serveWebSocket(w http.ResponseWriter, req *http.Request) {
+	ws, err := wsUpgrade(w, req)
+	if err != nil {
+		return
+	}
+	defer ws.close()
+
+	// One pending generation per browser; a newer one replaces it
+	frames := make(chan []byte, 1)
+	r.mu.Lock()
+	r.clients[frames] = true
+	if r.latest != nil {
+		frames <- r.latest
+	}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.clients, frames)
+		r.mu.Unlock()
+	}()
+
+	// The browser only ever sends pings and the close of the connection
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			opcode, payload, err := ws.readFrame()
+			if err != nil || opcode == wsClose {
+				return
+			}
+			if opcode == wsPing {
+				ws.writeFrame(wsPong, payload)
+			}
+		}
+	}()
+
+	if ws.writeFrame(wsText, r.hello) != nil {
+		return
+	}
+	for {
+		select {
+		case f := <-frames:
+			if ws.writeFrame(wsText, f) != nil {
+				return
+			}
+		case <-gone:
+			ws.writeFrame(wsClose, nil)
+			return
+		}
+	}
+}
+
+func (r *serveRenderer) Render(world engine.World, gen int) error {
+	if wait := r.delay - time.Since(r.last); !r.last.IsZero() && wait > 0 {
+		time.Sleep(wait)
+	}
+	r.last = time.Now()
+
+	f := serveFrame{Type: "frame", Gen: gen, Cells: []int{}}
+	for _, c := range world.LiveCells() {
+		f.Cells = append(f.Cells, c.X, c.Y)
+	}
+	f.Population = len(f.Cells) / 2
+	for _, c := range render.DecayingCells(world) {
+		f.Decaying = append(f.Decaying, c.X, c.Y, int(world[c].State))
+	}
+	msg, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latest = msg
+	for frames := range r.clients {
+		select {
+		case <-frames:
+		default:
+		}
+		frames <- msg
+	}
+	return nil
+}
+
+// close keeps showing the last generation until the user interrupts gol,
+// a demo is not over just because the run is
+func (r *serveRenderer) Close() error {
+	fmt.Fprintln(os.Stderr, "run finished, press Ctrl-C to stop serving")
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	<-interrupt
+	signal.Stop(interrupt)
+	return r.srv.Close()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// A runState is a checkpoint of a run, written by -save and continued by
+// -resume. The live cells are stored as a list of x,y pairs, the decaying
+// cells of a Generations rule as x,y,state triples.
+type runState struct {
+	Pattern    string   `json:"pattern"`
+	Rule       string   `json:"rule"`
+	Seed       int64    `json:"seed,omitempty"` // seed of the random soup the run started from
+	RNG        string   `json:"rng,omitempty"`  // generator of that soup
+	Generation int      `json:"generation"`
+	Cells      [][2]int `json:"cells"`
+	Decaying   [][3]int `json:"decaying,omitempty"`
+}
+
+// saveState writes the world at generation gen of the run to path
+func saveState(path string, cfg config, world engine.World, gen int) error {
+	s := runState{
+		Pattern:    cfg.pattern.Name,
+		Rule:       cfg.engine.Rule.String(),
+		Seed:       cfg.seed,
+		RNG:        cfg.rng,
+		Generation: gen,
+		Cells:      [][2]int{},
+	}
+	for _, c := range world.LiveCells() {
+		s.Cells = append(s.Cells, [2]int{c.X, c.Y})
+	}
+	for c, cell := range world {
+		if cell.State > 0 {
+			s.Decaying = append(s.Decaying, [3]int{c.X, c.Y, int(cell.State)})
+		}
+	}
+	// In a fixed order, like the live cells
+	slices.SortFunc(s.Decaying, func(a, b [3]int) int {
+		if a[1] != b[1] {
+			return cmp.Compare(a[1], b[1])
+		}
+		return cmp.Compare(a[0], b[0])
+	})
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0666)
+}
+
+// loadState reads a state written by saveState
+func loadState(path string) (runState, error) {
+	var s runState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("%s: %v", path, err)
+	}
+	if s.Generation < 0 {
+		return s, fmt.Errorf("%s: invalid generation %d", path, s.Generation)
+	}
+	for _, d := range s.Decaying {
+		if d[2] < 1 || d[2] > engine.MaxStates-2 {
+			return s, fmt.Errorf("%s: invalid decay state %d of cell %d,%d", path, d[2], d[0], d[1])
+		}
+	}
+	return s, nil
+}
+
+// pattern returns the live cells of the state as a pattern, to be placed
+// into the world at the origin
+func (s runState) pattern() pattern.Pattern {
+	p := pattern.Pattern{Name: s.Pattern, Rule: s.Rule}
+	for _, c := range s.Cells {
+		p.Cells = append(p.Cells, engine.Coord{X: c[0], Y: c[1]})
+	}
+	return p
+}
+
+// decaying returns the decaying cells of the state
+func (s runState) decaying() engine.World {
+	world := make(engine.World, len(s.Decaying))
+	for _, d := range s.Decaying {
+		world[engine.Coord{X: d[0], Y: d[1]}] = engine.Cell{State: uint8(d[2])}
+	}
+	return world
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// statsExporter writes a CSV row of statistics for every generation: the
+// population, the births and deaths since the generation before, the
+// bounding box of the live cells and the density of the bounding box.
+// Then comes a column for each detector with its events since the
+// generation before. With -step the births and deaths are counted against
+// the previous frame.
+type statsExporter struct {
+	c         io.Closer // nil for stderr
+	w         *csv.Writer
+	prev      map[engine.Coord]bool
+	detectors []detector
+	first     int // generation of the first row
+	last      int // generation of the last row
+}
+
+func newStatsExporter(path string, world engine.World, gen int, detectors []detector) (*statsExporter, error) {
+	e := &statsExporter{detectors: detectors, first: gen, last: gen}
+	if path == "-" {
+		e.w = csv.NewWriter(os.Stderr)
+	} else {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		e.c, e.w = f, csv.NewWriter(f)
+	}
+	header := []string{"gen", "population", "births", "deaths", "min_x", "min_y", "max_x", "max_y", "density"}
+	for _, d := range detectors {
+		header = append(header, d.name)
+	}
+	e.w.Write(header)
+	e.prev = liveSet(world)
+	for i := range e.detectors {
+		e.detectors[i].start(e.prev, world)
+	}
+	e.write(world, gen, 0, 0, make([]int, len(detectors)))
+	return e, nil
+}
+
+func (e *statsExporter) export(world engine.World, gen int) error {
+	live := liveSet(world)
+	births, deaths := 0, 0
+	for c := range live {
+		if !e.prev[c] {
+			births++
+		}
+	}
+	for c := range e.prev {
+		if !live[c] {
+			deaths++
+		}
+	}
+	events := make([]int, len(e.detectors))
+	for i := range e.detectors {
+		events[i] = e.detectors[i].count(e.prev, live, world, gen)
+	}
+	e.prev, e.last = live, gen
+	e.write(world, gen, births, deaths, events)
+	if e.c == nil {
+		// Flush every row, so stderr can be watched while the run goes on
+		e.w.Flush()
+	}
+	return e.w.Error()
+}
+
+func (e *statsExporter) write(world engine.World, gen, births, deaths int, events []int) {
+	s := statsOf(world, gen)
+	density := 0.0
+	if s.pop > 0 {
+		density = float64(s.pop) / float64(s.width*s.height)
+	}
+	row := []string{
+		strconv.Itoa(gen), strconv.Itoa(s.pop), strconv.Itoa(births), strconv.Itoa(deaths),
+		strconv.Itoa(s.min.X), strconv.Itoa(s.min.Y), strconv.Itoa(s.max.X), strconv.Itoa(s.max.Y),
+		strconv.FormatFloat(density, 'f', 4, 64),
+	}
+	for _, n := range events {
+		row = append(row, strconv.Itoa(n))
+	}
+	e.w.Write(row)
+}
+
+func (e *statsExporter) close() error {
+	e.w.Flush()
+	err := e.w.Error()
+	if e.c != nil {
+		if cerr := e.c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	for _, d := range e.detectors {
+		d.report(os.Stderr, e.last-e.first)
+	}
+	return err
+}
+
+// liveSet returns the coordinates of the live cells of the world
+func liveSet(world engine.World) map[engine.Coord]bool {
+	live := make(map[engine.Coord]bool, len(world))
+	for c, cell := range world {
+		if cell.Alive {
+			live[c] = true
+		}
+	}
+	return live
+}
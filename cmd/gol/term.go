@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/render"
+)
+
+// termRenderer animates the world in the terminal. Every character shows
+// two cells above each other with the Unicode half blocks, and every
+// frame is drawn over the previous one from the top left corner. Frames
+// are at least delay apart.
+type termRenderer struct {
+	w     *bufio.Writer
+	h     int          // the view runs from -h to h in both directions
+	pan   engine.Coord // centre of the view
+	note  string       // shown after the status line
+	opts  render.Options
+	delay time.Duration
+	last  time.Time
+
+	world engine.World // the generation on screen
+	gen   int
+}
+
+func newTermRenderer(w io.Writer, d int, opts render.Options, delay time.Duration) *termRenderer {
+	r := &termRenderer{w: bufio.NewWriter(w), h: d / 2, opts: opts, delay: delay}
+	// Hide the cursor and clear the screen
+	fmt.Fprint(r.w, "\x1b[?25l\x1b[2J")
+	return r
+}
+
+// termColor returns the escape sequence selecting a #rrggbb color for the
+// foreground, or the background if bg is set
+func termColor(s string, bg bool) string {
+	c := render.ParseColor(s)
+	layer := 38
+	if bg {
+		layer = 48
+	}
+	return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, c.R, c.G, c.B)
+}
+
+func (r *termRenderer) Render(world engine.World, gen int) error {
+	if wait := r.delay - time.Since(r.last); !r.last.IsZero() && wait > 0 {
+		time.Sleep(wait)
+	}
+	r.last = time.Now()
+	r.world, r.gen = world, gen
+	return r.draw()
+}
+
+// draw draws the generation on screen again, e.g. after the view moved
+func (r *termRenderer) draw() error {
+	t := r.opts.Theme
+	fmt.Fprint(r.w, "\x1b[H", termColor(t.Cell, false), termColor(t.Background, true))
+	top, bottom := r.pan.Y+r.h, r.pan.Y-r.h
+	alive := func(x, y int) bool {
+		return y >= bottom && r.world[engine.Coord{X: x, Y: y}].Alive
+	}
+	// Colored by age, or with the decaying cells of a Generations rule,
+	// every character is an upper half block in the color of the upper
+	// cell on the color of the lower one. The escape sequences are only
+	// written when the colors change.
+	colored := r.opts.Ages || r.opts.States > 2
+	color := func(x, y int) string {
+		cell := r.world[engine.Coord{X: x, Y: y}]
+		switch {
+		case y < bottom:
+		case cell.Alive && r.opts.Ages:
+			return render.ColorHex(render.AgeColor(t, cell.Age))
+		case cell.Alive:
+			return t.Cell
+		case cell.State > 0 && r.opts.States > 2:
+			return render.ColorHex(render.DecayColor(t, cell.State, r.opts.States))
+		}
+		return t.Background
+	}
+	fg, bg := t.Cell, t.Background
+	// The largest y is at the top, as in the other renderers
+	for y := top; y >= bottom; y -= 2 {
+		for x := r.pan.X - r.h; x <= r.pan.X+r.h; x++ {
+			if colored {
+				if c := color(x, y); c != fg {
+					fg = c
+					r.w.WriteString(termColor(fg, false))
+				}
+				if c := color(x, y-1); c != bg {
+					bg = c
+					r.w.WriteString(termColor(bg, true))
+				}
+				r.w.WriteString("▀")
+				continue
+			}
+			switch top, bottom := alive(x, y), alive(x, y-1); {
+			case top && bottom:
+				r.w.WriteString("█")
+			case top:
+				r.w.WriteString("▀")
+			case bottom:
+				r.w.WriteString("▄")
+			default:
+				r.w.WriteByte(' ')
+			}
+		}
+		r.w.WriteString("\r\n")
+	}
+
+	pop := 0
+	for _, cell := range r.world {
+		if cell.Alive {
+			pop++
+		}
+	}
+	// Clear the rest of the status line, it may have been longer before
+	fmt.Fprintf(r.w, "\x1b[0mgeneration %d, population %d%s\x1b[K", r.gen, pop, r.note)
+	return r.w.Flush()
+}
+
+// close restores the colors and the cursor of the terminal
+func (r *termRenderer) Close() error {
+	fmt.Fprint(r.w, "\x1b[0m\x1b[?25h\n")
+	return r.w.Flush()
+}
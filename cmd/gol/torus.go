@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// parseTorusSizes parses a comma-separated list of torus sizes, each
+// written as WxH or as N for an N x N torus
+func parseTorusSizes(s string) ([]engine.Torus, error) {
+	var tori []engine.Torus
+	for _, size := range strings.Split(s, ",") {
+		w, h, found := strings.Cut(strings.TrimSpace(size), "x")
+		if !found {
+			h = w
+		}
+		width, errW := strconv.Atoi(w)
+		height, errH := strconv.Atoi(h)
+		if errW != nil || errH != nil || width < 1 || height < 1 {
+			return nil, fmt.Errorf("invalid torus size %q, expected WxH or N", size)
+		}
+		tori = append(tori, engine.Torus{Width: width, Height: height})
+	}
+	return tori, nil
+}
+
+// divergence runs the world on the infinite plane and on each of the tori
+// for ticks generations and returns the first generation each torus
+// differs from the plane folded onto it, or -1 for a torus that follows
+// the plane all the way
+func divergence(rule engine.Rule, world engine.World, tori []engine.Torus, ticks int) []int {
+	plane := engine.Engine{Rule: rule}
+	engines := make([]engine.Engine, len(tori))
+	worlds := make([]engine.World, len(tori))
+	first := make([]int, len(tori))
+	running := len(tori)
+	for i := range tori {
+		engines[i] = engine.Engine{Rule: rule, Torus: &tori[i]}
+		worlds[i] = tori[i].Fold(world)
+		first[i] = -1
+	}
+
+	for gen := 0; running > 0; gen++ {
+		for i, t := range tori {
+			if first[i] < 0 && !sameWorld(t.Fold(world), worlds[i]) {
+				first[i] = gen
+				running--
+			}
+		}
+		if gen == ticks {
+			break
+		}
+		world = plane.Tick(world)
+		for i := range tori {
+			if first[i] < 0 {
+				worlds[i] = engines[i].Tick(worlds[i])
+			}
+		}
+	}
+	return first
+}
+
+// sameWorld tells if the two worlds have the same live and decaying cells
+func sameWorld(a, b engine.World) bool {
+	n := 0
+	for c, cell := range a {
+		if !cell.Alive && cell.State == 0 {
+			continue
+		}
+		if o := b[c]; o.Alive != cell.Alive || o.State != cell.State {
+			return false
+		}
+		n++
+	}
+	for _, cell := range b {
+		if cell.Alive || cell.State > 0 {
+			n--
+		}
+	}
+	return n == 0
+}
+
+// runTorus implements the torus subcommand: it runs a pattern on the
+// infinite plane and on tori of several sizes and reports the generation
+// each torus stops following the plane, to pick a torus large enough to
+// stand in for the plane
+func runTorus(args []string) error {
+	fs := flag.NewFlagSet("torus", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: gol torus [flags]\n\nCompares a pattern on tori of several sizes with the infinite plane.\nThe cells of a torus are centred on the origin, -center puts the pattern there too.\n\n")
+		fs.PrintDefaults()
+	}
+	loadPattern := patternFlags(fs)
+	sizesOpt := fs.String("sizes", "32,64,128,256,512", "comma-separated `sizes` of the tori, WxH or N for N x N")
+	ticks := fs.Int("ticks", 1000, "number of generations to compare")
+	ruleOpt := fs.String("rule", "", "life-like `rule` in B/S notation, defaults to the rule of the pattern file, or B3/S23")
+	fs.Parse(args)
+
+	p, err := loadPattern()
+	if err != nil {
+		return err
+	}
+	tori, err := parseTorusSizes(*sizesOpt)
+	if err != nil {
+		return err
+	}
+
+	rule := engine.Conway
+	if *ruleOpt == "" {
+		*ruleOpt = p.Rule
+	}
+	if *ruleOpt != "" {
+		if rule, err = engine.ParseRule(*ruleOpt); err != nil {
+			return err
+		}
+	}
+	if rule.Birth[0] {
+		return fmt.Errorf("the rule %s with B0 is not supported, it needs -engine dense", rule)
+	}
+
+	first := divergence(rule, p.World(), tori, *ticks)
+
+	fmt.Printf("# %s on the plane and on tori, rule %s, ticks=%d\n", p.Name, rule, *ticks)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "torus\tdiverges at")
+	safe := -1
+	for i, t := range tori {
+		gen := "never"
+		if first[i] >= 0 {
+			gen = strconv.Itoa(first[i])
+		} else if safe < 0 || t.Width*t.Height < tori[safe].Width*tori[safe].Height {
+			safe = i
+		}
+		fmt.Fprintf(tw, "%dx%d\t%s\n", t.Width, t.Height, gen)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if safe < 0 {
+		fmt.Fprintf(os.Stderr, "every torus diverges from the plane within %d generations\n", *ticks)
+	} else {
+		fmt.Fprintf(os.Stderr, "the %s is the smallest to follow the plane for all %d generations\n", tori[safe], *ticks)
+	}
+	return nil
+}
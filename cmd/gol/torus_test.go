@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+func TestDivergence(t *testing.T) {
+	// A glider flies around any torus it fits on as it flies on the
+	// plane, the R-pentomino soon fills a small torus
+	glider, _ := pattern.Named("glider")
+	r, _ := pattern.Named("r-pentomino")
+	tori := []engine.Torus{{Width: 8, Height: 6}, {Width: 16, Height: 16}}
+	if first := divergence(engine.Conway, glider.World(), tori, 100); !slices.Equal(first, []int{-1, -1}) {
+		t.Errorf("glider diverges at %v, want never", first)
+	}
+	first := divergence(engine.Conway, centered(r).World(), tori, 100)
+	if first[0] < 0 || first[1] < 0 || first[0] > first[1] {
+		t.Errorf("r-pentomino diverges at %v, want earlier on the smaller torus", first)
+	}
+
+	// Cells far apart on the plane that make a blinker on the torus
+	wide := pattern.Pattern{Cells: []engine.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 10, Y: 0}}}
+	if first := divergence(engine.Conway, wide.World(), tori[:1], 10); first[0] != 1 {
+		t.Errorf("pattern wider than the torus diverges at %d, want 1", first[0])
+	}
+}
+
+func TestParseTorusSizes(t *testing.T) {
+	tori, err := parseTorusSizes("32, 64x48")
+	if err != nil || !slices.Equal(tori, []engine.Torus{{Width: 32, Height: 32}, {Width: 64, Height: 48}}) {
+		t.Errorf("sizes parse as %v, %v", tori, err)
+	}
+	for _, s := range []string{"", "x", "0", "8x", "8x-1", "a"} {
+		if _, err := parseTorusSizes(s); err == nil {
+			t.Errorf("%q parses, want an error", s)
+		}
+	}
+}
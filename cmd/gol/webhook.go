@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds the time a webhook may take to answer, so a dead
+// endpoint cannot hold up the end of a run
+const webhookTimeout = 10 * time.Second
+
+// A webhookEvent is posted as JSON to the -webhook URL when a run
+// completes or fails, and when a notify alert fires. A command line that
+// does not check out never starts a run and is only reported on the
+// terminal.
+type webhookEvent struct {
+	Event      string `json:"event"` // completed, failed or alert
+	Pattern    string `json:"pattern"`
+	Seed       int64  `json:"seed,omitempty"`
+	Generation int    `json:"generation"`
+	Population int    `json:"population"`
+	Alert      string `json:"alert,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// newWebhookEvent describes the state of a run at a generation
+func newWebhookEvent(cfg config, event string, s worldStats) webhookEvent {
+	e := webhookEvent{Event: event, Pattern: cfg.pattern.Name, Generation: s.gen, Population: s.pop}
+	if cfg.random {
+		e.Seed = cfg.seed
+	}
+	return e
+}
+
+// postWebhook posts the event to url and checks that it was accepted
+func postWebhook(url string, e webhookEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook: %s answered %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// The little of the WebSocket protocol (RFC 6455) the server needs: the
+// opening handshake, unfragmented text messages from the server and
+// reading the client's frames, which are only ever answered if they are a
+// ping or a close.
+
+// wsGUID is appended to the key of the client to compute the accept key
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+	wsPing  = 0x9
+	wsPong  = 0xa
+)
+
+// wsMaxClientFrame bounds the frames read from a client, who has no
+// business sending more than control frames
+const wsMaxClientFrame = 1 << 16
+
+// A wsConn is the server side of a WebSocket connection
+type wsConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	mu   sync.Mutex // held writing a frame, pongs come from the reading goroutine
+}
+
+// wsUpgrade answers the opening handshake of a WebSocket client and
+// takes over the connection of the request
+func wsUpgrade(w http.ResponseWriter, req *http.Request) (*wsConn, error) {
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "expected a WebSocket handshake", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: not a handshake")
+	}
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "cannot take over the connection", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: connection cannot be hijacked")
+	}
+	conn, rw, err := h.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum([]byte(key + wsGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(sum[:]))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, r: rw.Reader}, nil
+}
+
+// writeFrame writes a single unmasked frame, as servers have to. It may be
+// called from several goroutines.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	header := []byte{0x80 | opcode, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	n := len(payload)
+	switch {
+	case n < 126:
+		header[1] = byte(n)
+		header = header[:2]
+	case n < 1<<16:
+		header[1] = 126
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+		header = header[:4]
+	default:
+		header[1] = 127
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// readFrame reads the next frame of the client and unmasks its payload
+func (c *wsConn) readFrame() (opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode = head[0] & 0x0f
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if n > wsMaxClientFrame {
+		return 0, nil, fmt.Errorf("websocket: frame of %d bytes from client", n)
+	}
+	if head[1]&0x80 == 0 {
+		return 0, nil, fmt.Errorf("websocket: unmasked frame from client")
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+func (c *wsConn) close() error {
+	return c.conn.Close()
+}
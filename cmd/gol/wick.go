@@ -0,0 +1,234 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// wickMargin is the number of dead rows above and below a horizontal
+// wick on its torus, room for the burn to spread into
+const wickMargin = 10
+
+// A wick is a pattern tiled along a line, closed into a ring on a torus.
+// Tile k has its origin at at + k*step.
+type wick struct {
+	tile  pattern.Pattern
+	step  engine.Coord
+	tiles int
+	at    engine.Coord
+	torus engine.Torus
+}
+
+// newWick lays out the tiles of a wick. The torus is as wide as the wick
+// is long, so the last tile connects to the first one, and for a wick
+// running at a slant as high as it climbs.
+func newWick(tile pattern.Pattern, step engine.Coord, tiles int) wick {
+	_, hi := tile.Bounds()
+	t := engine.Torus{Width: tiles * step.X, Height: tiles * max(step.Y, -step.Y)}
+	if step.Y == 0 {
+		t.Height = hi.Y + 1 + 2*wickMargin
+	}
+	return wick{tile, step, tiles, engine.Coord{X: -t.Width / 2, Y: -(hi.Y + 1) / 2}, t}
+}
+
+// origin returns the origin of tile k
+func (w wick) origin(k int) engine.Coord {
+	return engine.Coord{X: w.at.X + k*w.step.X, Y: w.at.Y + k*w.step.Y}
+}
+
+// index returns the tile a cell of the torus lies in
+func (w wick) index(c engine.Coord) int {
+	x := ((c.X-w.at.X)%w.torus.Width + w.torus.Width) % w.torus.Width
+	return x / w.step.X
+}
+
+// world returns the whole, unlit wick
+func (w wick) world() engine.World {
+	world := make(engine.World)
+	for k := 0; k < w.tiles; k++ {
+		w.tile.Place(world, w.origin(k))
+	}
+	return w.torus.Fold(world)
+}
+
+// A burn is what became of a lit wick
+type burn struct {
+	front    []int // foremost tile that differs from the unlit wick, by generation
+	velocity float64
+	debris   int // live cells left behind the front
+}
+
+// burnWick lights the wick by cutting out its first tile, and sparking the
+// cells at spark relative to the second tile, and follows the burn through
+// the first half of the wick. The other end of the cut may burn as well,
+// in the other direction, and is left alone.
+func burnWick(w wick, rule engine.Rule, spark []engine.Coord, ticks int) burn {
+	e := engine.Engine{Rule: rule, Torus: &w.torus, Workers: 1}
+	unlit := w.world()
+	lit := w.world()
+	w.tile.Erase(lit, w.torus.Wrap(w.origin(0)))
+	for _, c := range spark {
+		s := w.torus.Wrap(engine.Coord{X: w.origin(1).X + c.X, Y: w.origin(1).Y + c.Y})
+		if lit[s].Alive {
+			delete(lit, s)
+		} else {
+			lit[s] = engine.Cell{Alive: true}
+		}
+	}
+
+	var b burn
+	half := w.tiles / 2
+	for gen := 1; gen <= ticks; gen++ {
+		unlit, lit = e.Tick(unlit), e.Tick(lit)
+		front := 0
+		for _, world := range []engine.World{lit, unlit} {
+			for c, cell := range world {
+				if cell.Alive && lit[c].Alive != unlit[c].Alive {
+					if k := w.index(c); k < half {
+						front = max(front, k)
+					}
+				}
+			}
+		}
+		b.front = append(b.front, front)
+		if front >= half-2 {
+			break
+		}
+	}
+
+	// The velocity is measured over the second half of the burn, when it
+	// has settled down after the spark
+	n := len(b.front)
+	if n >= 2 {
+		mid := n / 2
+		tiles := b.front[n-1] - b.front[mid-1]
+		b.velocity = float64(tiles*max(w.step.X, w.step.Y, -w.step.Y)) / float64(n-mid)
+	}
+	last := b.front[n-1]
+	for c, cell := range lit {
+		if k := w.index(c); cell.Alive && k < last-2 {
+			b.debris++
+		}
+	}
+	return b
+}
+
+// speedOfLight writes a velocity in cells per generation as a fraction of
+// c, if it is one with a small denominator
+func speedOfLight(v float64) string {
+	for q := 1; q <= 12; q++ {
+		p := v * float64(q)
+		if r := float64(int(p + 0.5)); r > 0 && p-r < 1e-9 && r-p < 1e-9 {
+			switch {
+			case q == 1 && r == 1:
+				return "c"
+			case q == 1:
+				return fmt.Sprintf("%.0fc", r)
+			case r == 1:
+				return fmt.Sprintf("c/%d", q)
+			}
+			return fmt.Sprintf("%.0fc/%d", r, q)
+		}
+	}
+	return fmt.Sprintf("%.4fc", v)
+}
+
+// parseCoordPair parses x,y
+func parseCoordPair(s string) (engine.Coord, error) {
+	x, y, found := strings.Cut(s, ",")
+	dx, errX := strconv.Atoi(x)
+	dy, errY := strconv.Atoi(y)
+	if !found || errX != nil || errY != nil {
+		return engine.Coord{}, fmt.Errorf("invalid coordinate %q, expected x,y", s)
+	}
+	return engine.Coord{X: dx, Y: dy}, nil
+}
+
+// runWick implements the wick subcommand: it tiles a wick along a line,
+// lights one end and reports how fast and how clean it burns
+func runWick(args []string) error {
+	fs := flag.NewFlagSet("wick", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: gol wick [flags]\n\nLights a wick or fuse at one end and measures how it burns.\n\n")
+		fs.PrintDefaults()
+	}
+	loadPattern := patternFlags(fs)
+	stepOpt := fs.String("step", "", "offset `dx,dy` from one tile of the wick to the next, dx > 0; defaults to the width of the pattern along x")
+	tiles := fs.Int("tiles", 64, "number of tiles in the wick")
+	ticks := fs.Int("ticks", 1000, "give up on the burn after `n` generations")
+	sparkOpt := fs.String("spark", "", "flip the cells at `x,y;...` of the second tile, for wicks that do not burn from a plain cut")
+	ruleOpt := fs.String("rule", "", "life-like `rule` in B/S notation, defaults to the rule of the pattern file, or B3/S23")
+	fs.Parse(args)
+
+	p, err := loadPattern()
+	if err != nil {
+		return err
+	}
+	p = p.Normalize()
+	if len(p.Cells) == 0 {
+		return fmt.Errorf("the wick has no live cells")
+	}
+	_, hi := p.Bounds()
+	step := engine.Coord{X: hi.X + 1}
+	if *stepOpt != "" {
+		if step, err = parseCoordPair(*stepOpt); err != nil {
+			return err
+		}
+	}
+	if step.X < 1 || *tiles < 8 {
+		return fmt.Errorf("a wick needs a step with dx > 0 and at least 8 tiles")
+	}
+	if *ticks < 1 {
+		return fmt.Errorf("invalid number of ticks %d", *ticks)
+	}
+	var spark []engine.Coord
+	if *sparkOpt != "" {
+		s, err := pattern.ParseCoordinates(*sparkOpt)
+		if err != nil {
+			return err
+		}
+		spark = s.Cells
+	}
+
+	rule := engine.Conway
+	if *ruleOpt == "" {
+		*ruleOpt = p.Rule
+	}
+	if *ruleOpt != "" {
+		if rule, err = engine.ParseRule(*ruleOpt); err != nil {
+			return err
+		}
+	}
+	if rule.Birth[0] {
+		return fmt.Errorf("the rule %s with B0 is not supported, it needs -engine dense", rule)
+	}
+
+	w := newWick(p, step, *tiles)
+	b := burnWick(w, rule, spark, *ticks)
+
+	fmt.Printf("# %s wick of %d tiles %d,%d apart on a %s, rule %s\n", p.Name, *tiles, step.X, step.Y, w.torus, rule)
+	n := len(b.front)
+	last := b.front[n-1]
+	switch {
+	case last == 0:
+		fmt.Println("does not burn")
+	case b.velocity == 0:
+		fmt.Printf("burns %d tiles and stops\n", last)
+	default:
+		clean := "clean"
+		if b.debris > 0 {
+			clean = fmt.Sprintf("leaving %d cells of debris", b.debris)
+		}
+		fmt.Printf("burns at %.4f cells/generation (%s), %s\n", b.velocity, speedOfLight(b.velocity), clean)
+		if last < *tiles/2-2 {
+			fmt.Printf("burnt %d tiles in %d generations\n", last, n)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// wrapGap returns how far the live cells of a world on the torus are from
+// wrapping around into themselves: the widest band of columns without live
+// cells, or the widest band of rows, whichever is narrower. The bands run
+// across the edges of the torus. Once no band is left, the pattern touches
+// itself around the torus and no longer evolves as on the infinite plane.
+func wrapGap(t engine.Torus, world engine.World) int {
+	cols, rows := make([]bool, t.Width), make([]bool, t.Height)
+	for c, cell := range world {
+		if cell.Alive {
+			cols[c.X+t.Width/2], rows[c.Y+t.Height/2] = true, true
+		}
+	}
+	return min(widestGap(cols), widestGap(rows))
+}
+
+// widestGap returns the longest run of false in the cyclic slice
+func widestGap(used []bool) int {
+	widest, run := 0, 0
+	// Twice around, so that a run across the end is counted as a whole
+	for i := 0; i < 2*len(used); i++ {
+		if used[i%len(used)] {
+			run = 0
+			continue
+		}
+		run++
+		widest = max(widest, run)
+	}
+	return min(widest, len(used))
+}
+
+// checkWrap warns once the live cells of the world, generation gen, come
+// within -wrap-margin cells of wrapping around the torus into themselves,
+// or fails the run with -strict-topology. It tells if it warned.
+func checkWrap(cfg config, world engine.World, gen int) (bool, error) {
+	gap := wrapGap(*cfg.engine.Torus, world)
+	if gap >= cfg.wrapMargin {
+		return false, nil
+	}
+	msg := fmt.Sprintf("generation %d: the live cells are %d cells from wrapping around the %s into themselves, from here on the run may differ from the infinite plane", gen, gap, cfg.engine.Torus)
+	if cfg.strictTopology {
+		return true, errors.New(msg)
+	}
+	fmt.Fprintf(os.Stderr, "warning: %s\n", msg)
+	return true, nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+func TestWrapGap(t *testing.T) {
+	torus := engine.Torus{Width: 10, Height: 20}
+	for _, tc := range []struct {
+		what  string
+		cells []engine.Coord
+		want  int
+	}{
+		{"no cells", nil, 10},
+		{"one cell", []engine.Coord{{X: 0, Y: 0}}, 9},
+		// The right edge of the torus is next to the left one
+		{"cells at both edges", []engine.Coord{{X: -5, Y: 0}, {X: 4, Y: 0}}, 8},
+		{"cells 3 columns apart", []engine.Coord{{X: -5, Y: 0}, {X: -2, Y: 0}, {X: 1, Y: 0}, {X: 4, Y: 0}}, 2},
+		{"a full column", []engine.Coord{{X: 0, Y: -10}, {X: 0, Y: -5}, {X: 0, Y: 0}, {X: 0, Y: 5}}, 4},
+	} {
+		world := make(engine.World)
+		for _, c := range tc.cells {
+			world[c] = engine.Cell{Alive: true}
+		}
+		if got := wrapGap(torus, world); got != tc.want {
+			t.Errorf("%s: gap %d, want %d", tc.what, got, tc.want)
+		}
+	}
+}
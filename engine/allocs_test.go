@@ -0,0 +1,93 @@
+package engine
+
+import "testing"
+
+// An allocBudget is the most heap allocations one call of tick may make,
+// so that a change making the hot path of an engine allocate more does not
+// go unnoticed. The budgets have some headroom over the measured counts,
+// which vary a little from run to run with the random seeds of the maps.
+type allocBudget struct {
+	name   string
+	budget float64
+	tick   func() func() // prepares the world and returns one tick of it
+}
+
+var allocBudgets = []allocBudget{
+	{"map tick", 300, func() func() {
+		world := settledSoup(128)
+		e := Engine{Rule: Conway, Workers: 1}
+		return func() { e.Tick(world) }
+	}},
+	{"torus tick", 300, func() func() {
+		world := settledSoup(128)
+		e := Engine{Rule: Conway, Torus: &Torus{Width: 128, Height: 128}, Workers: 1}
+		return func() { e.Tick(world) }
+	}},
+	{"parallel tick", 1500, func() func() {
+		world := make(World)
+		FillRandomSoup(world, 7, 300, 1)
+		e := Engine{Rule: Conway, Workers: 4}
+		return func() { e.Tick(world) }
+	}},
+	{"incremental step", 50, func() func() {
+		// The maps are reused, they only grow with the active spots
+		in := NewIncremental(Engine{Rule: Conway}, settledSoup(128))
+		return in.Step
+	}},
+	{"dense step", 20, func() func() {
+		// Only the goroutines of the workers, the bitboards are swapped
+		t := &Torus{Width: 128, Height: 128}
+		d := NewDense(Engine{Rule: Conway, Torus: t, Workers: 4}, t.Fold(settledSoup(128)))
+		return d.Step
+	}},
+	{"hashlife step", 0, func() func() {
+		// Once the blinker is memoized, stepping it must not allocate at all
+		blinker := World{{X: 0, Y: 0}: {Alive: true}, {X: 1, Y: 0}: {Alive: true}, {X: 2, Y: 0}: {Alive: true}}
+		h := NewHashLife(Conway, blinker)
+		h.Step(64)
+		return func() { h.Step(64) }
+	}},
+}
+
+// settledSoup returns a random soup of size x size cells after 50
+// generations, when the first burst of births is over
+func settledSoup(size int) World {
+	world := make(World)
+	FillRandomSoup(world, 20150101, size, 1)
+	for i := 0; i < 50; i++ {
+		world = world.Tick()
+	}
+	return world
+}
+
+func TestAllocBudgets(t *testing.T) {
+	for _, b := range allocBudgets {
+		if n := testing.AllocsPerRun(20, b.tick()); n > b.budget {
+			t.Errorf("%s: %.0f allocations, budget %.0f", b.name, n, b.budget)
+		}
+	}
+}
+
+// benchmarkBudget runs the tick of the budget with the name
+func benchmarkBudget(b *testing.B, name string) {
+	for _, budget := range allocBudgets {
+		if budget.name != name {
+			continue
+		}
+		tick := budget.tick()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			tick()
+		}
+		return
+	}
+	b.Fatalf("no budget %s", name)
+}
+
+func BenchmarkMapTick(b *testing.B)         { benchmarkBudget(b, "map tick") }
+func BenchmarkTorusTick(b *testing.B)       { benchmarkBudget(b, "torus tick") }
+func BenchmarkParallelTick(b *testing.B)    { benchmarkBudget(b, "parallel tick") }
+func BenchmarkIncrementalStep(b *testing.B) { benchmarkBudget(b, "incremental step") }
+func BenchmarkDenseStep(b *testing.B)       { benchmarkBudget(b, "dense step") }
+func BenchmarkHashLifeStep(b *testing.B)    { benchmarkBudget(b, "hashlife step") }
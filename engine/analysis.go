@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"slices"
+)
+
+// DetectPeriod evolves the world until it repeats itself, up to maxGen
+// generations. A pattern that comes back moved, like a spaceship, counts
+// as repeating; shift is how far it moved in one period. ok is false if
+// the world did not repeat within maxGen generations.
+func DetectPeriod(world World, maxGen int) (period int, shift Coord, ok bool) {
+	return Engine{Rule: Conway}.DetectPeriod(world, maxGen)
+}
+
+// DetectPeriod is DetectPeriod for the rule and topology of the engine. A
+// world of a Generations rule repeats when its live cells do.
+func (e Engine) DetectPeriod(world World, maxGen int) (period int, shift Coord, ok bool) {
+	start := world.LiveCells()
+	if len(start) == 0 {
+		return 1, Coord{}, true
+	}
+	startMin, _ := Bounds(start)
+	startShape := normalized(start)
+
+	for gen := 1; gen <= maxGen; gen++ {
+		world = e.Tick(world)
+		cells := world.LiveCells()
+		if len(cells) == len(start) && slices.Equal(normalized(cells), startShape) {
+			min, _ := Bounds(cells)
+			return gen, Coord{min.X - startMin.X, min.Y - startMin.Y}, true
+		}
+	}
+	return 0, Coord{}, false
+}
+
+// normalized returns sorted cells moved so that their bounding box starts
+// at the origin. Moving keeps them sorted.
+func normalized(cells []Coord) []Coord {
+	min, _ := Bounds(cells)
+	moved := make([]Coord, len(cells))
+	for i, c := range cells {
+		moved[i] = Coord{c.X - min.X, c.Y - min.Y}
+	}
+	return moved
+}
+
+// Hamming counts the cells that are alive in exactly one of the worlds
+func Hamming(a, b World) int {
+	n := 0
+	for coord, cell := range a {
+		if cell.Alive && !b[coord].Alive {
+			n++
+		}
+	}
+	for coord, cell := range b {
+		if cell.Alive && !a[coord].Alive {
+			n++
+		}
+	}
+	return n
+}
+
+// Hash hashes the live cells of the world, and the decaying cells of a
+// Generations rule with their states, independently of the iteration
+// order of the map, so equal worlds hash equally on every platform
+func (world World) Hash() uint64 {
+	h := fnv.New64a()
+	var b [16]byte
+	for _, c := range world.LiveCells() {
+		binary.LittleEndian.PutUint64(b[:8], uint64(int64(c.X)))
+		binary.LittleEndian.PutUint64(b[8:], uint64(int64(c.Y)))
+		h.Write(b[:])
+	}
+	var decaying []Coord
+	for c, cell := range world {
+		if cell.State > 0 {
+			decaying = append(decaying, c)
+		}
+	}
+	SortCoords(decaying)
+	var d [17]byte // the coordinates and the state
+	for _, c := range decaying {
+		binary.LittleEndian.PutUint64(d[:8], uint64(int64(c.X)))
+		binary.LittleEndian.PutUint64(d[8:16], uint64(int64(c.Y)))
+		d[16] = world[c].State
+		h.Write(d[:])
+	}
+	return h.Sum64()
+}
+
+// A CycleDetector recognizes the generation from which on a run repeats
+// itself, as still lifes and oscillators do, by remembering the hashes of
+// the most recent generations. A world that comes back moved, like a
+// spaceship, does not count as a repetition.
+type CycleDetector struct {
+	window int
+	gens   map[uint64]int // generation of each remembered hash
+	recent []uint64       // remembered hashes, oldest first
+}
+
+// NewCycleDetector returns a detector finding periods up to window
+// generations
+func NewCycleDetector(window int) *CycleDetector {
+	return &CycleDetector{window: window, gens: make(map[uint64]int)}
+}
+
+// Observe records the world at generation gen. Generations must be
+// observed in order, one by one. If the world equals one of the last
+// window generations, Observe returns the period of the cycle it entered.
+// Worlds are compared by their Hash; a collision of two different worlds
+// is possible, but very unlikely.
+func (d *CycleDetector) Observe(world World, gen int) (period int, ok bool) {
+	h := world.Hash()
+	if g, found := d.gens[h]; found {
+		return gen - g, true
+	}
+	if len(d.recent) == d.window {
+		delete(d.gens, d.recent[0])
+		d.recent = d.recent[1:]
+	}
+	d.recent = append(d.recent, h)
+	d.gens[h] = gen
+	return 0, false
+}
@@ -0,0 +1,39 @@
+package engine
+
+import "testing"
+
+func TestDetectPeriod(t *testing.T) {
+	blinker := NewWorldFromCells(Coords(Coord{X: 0, Y: 0}, Coord{X: 1, Y: 0}, Coord{X: 2, Y: 0}))
+	glider := NewWorldFromCells(Coords(Coord{X: 1, Y: 0}, Coord{X: 2, Y: 1}, Coord{X: 0, Y: 2}, Coord{X: 1, Y: 2}, Coord{X: 2, Y: 2}))
+	block := NewWorldFromCells(Coords(Coord{X: 0, Y: 0}, Coord{X: 1, Y: 0}, Coord{X: 0, Y: 1}, Coord{X: 1, Y: 1}))
+	highLife := Engine{Rule: Rule{Birth: [9]bool{3: true, 6: true}, Survival: Conway.Survival}}
+
+	tests := []struct {
+		name   string
+		engine Engine
+		world  World
+		period int
+		shift  Coord
+	}{
+		{"block", Engine{Rule: Conway}, block, 1, Coord{}},
+		{"blinker", Engine{Rule: Conway}, blinker, 2, Coord{}},
+		{"glider", Engine{Rule: Conway}, glider, 4, Coord{X: 1, Y: 1}},
+		{"HighLife glider", highLife, glider, 4, Coord{X: 1, Y: 1}},
+		{"empty", Engine{Rule: Conway}, World{}, 1, Coord{}},
+	}
+	for _, tt := range tests {
+		period, shift, ok := tt.engine.DetectPeriod(tt.world, 30)
+		if !ok || period != tt.period || shift != tt.shift {
+			t.Errorf("%s: period %d moving by %v (%t), want %d moving by %v", tt.name, period, shift, ok, tt.period, tt.shift)
+		}
+	}
+
+	// B3/S2 eats a block, which never comes back
+	e := Engine{Rule: Rule{Birth: Conway.Birth, Survival: [9]bool{2: true}}}
+	if _, _, ok := e.DetectPeriod(block, 10); ok {
+		t.Errorf("a block under B3/S2 repeats")
+	}
+	if period, _, _ := DetectPeriod(blinker, 30); period != 2 {
+		t.Errorf("DetectPeriod finds period %d of a blinker, want 2", period)
+	}
+}
@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"math/bits"
+)
+
+// Dense computes generations on a torus kept as a bitboard: every row of
+// the torus is a run of 64 bit words with one bit per cell. The neighbours
+// of 64 cells are counted at once with bitwise adders, so dense worlds
+// like random soups are computed many times faster than in a map of
+// cells, at the cost of the memory and time of the dead cells as well.
+//
+// The bitboard holds every cell of the torus, so rules with B0 are
+// computed correctly, unlike in the map engine. Ages are not kept, and
+// Generations rules are not supported.
+type Dense struct {
+	birth    []int // the neighbour counts that make a dead cell alive
+	survival []int // and keep a live cell alive
+	torus    Torus
+	words    int    // per row
+	last     uint64 // mask of the cells in the last word of a row
+	gen      int
+	cells    []uint64 // row y of the torus starts at word y*words
+	next     []uint64
+	workers  int
+}
+
+// NewDense returns a bitboard engine for the rule and torus of e, with the
+// live cells of the world, which has to be folded onto the torus already.
+// The rows are divided among the workers of e.
+func NewDense(e Engine, world World) *Dense {
+	t := *e.Torus
+	d := &Dense{torus: t, words: (t.Width + 63) / 64, workers: max(e.Workers, 1)}
+	for n := 0; n <= 8; n++ {
+		if e.Rule.Birth[n] {
+			d.birth = append(d.birth, n)
+		}
+		if e.Rule.Survival[n] {
+			d.survival = append(d.survival, n)
+		}
+	}
+	d.last = ^uint64(0) >> (64*d.words - t.Width)
+	d.cells = make([]uint64, d.words*t.Height)
+	d.next = make([]uint64, len(d.cells))
+	for c, cell := range world {
+		if cell.Alive {
+			x, y := c.X+t.Width/2, c.Y+t.Height/2
+			d.cells[y*d.words+x/64] |= 1 << (x % 64)
+		}
+	}
+	return d
+}
+
+// row returns the words of row y, wrapping around the torus
+func (d *Dense) row(y int) []uint64 {
+	y = (y + d.torus.Height) % d.torus.Height
+	return d.cells[y*d.words : (y+1)*d.words]
+}
+
+// west returns word k of the row with every cell moved one to the east, so
+// that each bit holds the western neighbour of its cell
+func (d *Dense) west(row []uint64, k int) uint64 {
+	v := row[k] << 1
+	if k > 0 {
+		v |= row[k-1] >> 63
+	} else {
+		x := d.torus.Width - 1
+		v |= row[x/64] >> (x % 64) & 1
+	}
+	if k == d.words-1 {
+		v &= d.last
+	}
+	return v
+}
+
+// east returns word k of the row with every cell moved one to the west,
+// so that each bit holds the eastern neighbour of its cell
+func (d *Dense) east(row []uint64, k int) uint64 {
+	v := row[k] >> 1
+	if k < d.words-1 {
+		v |= row[k+1] << 63
+	} else {
+		v |= (row[0] & 1) << ((d.torus.Width - 1) % 64)
+	}
+	return v
+}
+
+// Step computes the next generation
+func (d *Dense) Step() {
+	h := d.torus.Height
+	forEachWorker(d.workers, func(w int) {
+		for y := w * h / d.workers; y < (w+1)*h/d.workers; y++ {
+			above, here, below := d.row(y-1), d.row(y), d.row(y+1)
+			for k := 0; k < d.words; k++ {
+				// The count of every cell as four bit planes
+				var s0, s1, s2, s3 uint64
+				for _, v := range [8]uint64{
+					d.west(above, k), above[k], d.east(above, k),
+					d.west(here, k), d.east(here, k),
+					d.west(below, k), below[k], d.east(below, k),
+				} {
+					c0 := s0 & v
+					s0 ^= v
+					c1 := s1 & c0
+					s1 ^= c0
+					c2 := s2 & c1
+					s2 ^= c1
+					s3 |= c2
+				}
+				count := func(n int) uint64 {
+					m := ^uint64(0)
+					for b, s := range [4]uint64{s0, s1, s2, s3} {
+						if n>>b&1 != 0 {
+							m &= s
+						} else {
+							m &^= s
+						}
+					}
+					return m
+				}
+
+				alive := here[k]
+				var next uint64
+				for _, n := range d.birth {
+					next |= count(n) &^ alive
+				}
+				for _, n := range d.survival {
+					next |= count(n) & alive
+				}
+				if k == d.words-1 {
+					next &= d.last
+				}
+				d.next[y*d.words+k] = next
+			}
+		}
+	})
+	d.cells, d.next = d.next, d.cells
+	d.gen++
+}
+
+// Generation returns the number of generations computed
+func (d *Dense) Generation() int {
+	return d.gen
+}
+
+// Population returns the number of live cells
+func (d *Dense) Population() int {
+	n := 0
+	for _, w := range d.cells {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// World returns the live cells of the current generation as a new world
+func (d *Dense) World() World {
+	world := make(World, d.Population())
+	for i, w := range d.cells {
+		y, k := i/d.words, i%d.words
+		for ; w != 0; w &= w - 1 {
+			x := k*64 + bits.TrailingZeros64(w)
+			world[Coord{x - d.torus.Width/2, y - d.torus.Height/2}] = Cell{true, 0, 0, 0}
+		}
+	}
+	return world
+}
@@ -0,0 +1,38 @@
+package engine
+
+import "testing"
+
+func TestDense(t *testing.T) {
+	// The bitboard has to agree with the map engine on tori whose width is
+	// and is not a multiple of the 64 cells of a word
+	for _, torus := range []Torus{{Width: 64, Height: 64}, {Width: 100, Height: 70}, {Width: 200, Height: 33}} {
+		e := Engine{Rule: Conway, Torus: &torus, Workers: 3}
+		world := make(World)
+		FillRandomSoup(world, 13, max(torus.Width, torus.Height), 1)
+		world = torus.Fold(world)
+		d := NewDense(e, world)
+		for gen := 1; gen <= 200; gen++ {
+			world = e.Tick(world)
+			d.Step()
+		}
+		if d.World().Hash() != world.Hash() {
+			t.Errorf("generation 200 of a soup on a %s differs from the map engine", torus)
+		}
+	}
+}
+
+func TestDenseB0(t *testing.T) {
+	// Under B0/S8 every dead cell with no live neighbours comes alive, so
+	// a block on an otherwise empty torus turns into its negative
+	rule, err := ParseRule("B0/S8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	torus := Torus{Width: 16, Height: 16}
+	block := Coords(Coord{X: 0, Y: 0}, Coord{X: 1, Y: 0}, Coord{X: 0, Y: 1}, Coord{X: 1, Y: 1})
+	d := NewDense(Engine{Rule: rule, Torus: &torus}, NewWorldFromCells(block))
+	d.Step()
+	if n, want := len(d.World().LiveCells()), 16*16-16; n != want {
+		t.Errorf("%d live cells after one generation, want %d", n, want)
+	}
+}
@@ -0,0 +1,41 @@
+package engine
+
+// An Engine computes generations of worlds with a rule of its choice and
+// on a topology of its choice. The World methods are the engine for
+// Conway's rule on the unbounded plane.
+type Engine struct {
+	Rule    Rule   // Conway for the Game of Life
+	Torus   *Torus // the topology of the world, nil for the unbounded plane
+	Workers int    // goroutines sharing the work on large worlds, 0 or 1 for none
+}
+
+// parallel tells if the engine spreads a tick of the world over its
+// workers
+func (e Engine) parallel(world World) bool {
+	return e.Workers > 1 && len(world) >= parallelMinCells
+}
+
+// wrap returns the function moving neighbours onto the topology, nil if
+// they need no moving
+func (e Engine) wrap() func(Coord) Coord {
+	if e.Torus == nil {
+		return nil
+	}
+	return e.Torus.Wrap
+}
+
+// Evolve computes the next generation of the world, keeping the dead
+// cells around the live ones. Only those dead cells are computed, so rules
+// with B0 are not computed correctly, they need the Dense engine.
+func (e Engine) Evolve(world World) World {
+	if e.parallel(world) {
+		return e.evolveParallel(world, nil)
+	}
+	wrap := e.wrap()
+	return world.inflate(wrap).countLiveNeighbours(wrap).ApplyRule(e.Rule)
+}
+
+// Tick computes the next generation of live cells in the world
+func (e Engine) Tick(world World) World {
+	return e.Evolve(world).Deflate()
+}
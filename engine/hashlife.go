@@ -0,0 +1,313 @@
+package engine
+
+// HashLife computes generations with Gosper's Hashlife algorithm. The
+// world is kept as a quadtree whose identical subtrees are shared, and the
+// future of every subtree is remembered once computed. Repetitive patterns
+// can so be advanced by millions of generations in a few steps, where the
+// map engine has to compute every single generation.
+//
+// Like the map engine Hashlife assumes that nothing is born from nothing,
+// so rules with B0 are not computed correctly. Generations rules are not
+// supported.
+type HashLife struct {
+	rule    Rule
+	nodes   map[quad]*hlNode
+	results map[hlResult]*hlNode
+	empty   []*hlNode // empty nodes by level
+	dead    *hlNode
+	alive   *hlNode
+	root    *hlNode // centred on the origin
+	gen     int64
+}
+
+// hashLifeMaxNodes is the number of nodes after which the nodes not used
+// by the current generation and the remembered results are dropped
+const hashLifeMaxNodes = 1 << 20
+
+// An hlNode is a square of 2^level x 2^level cells. Level 0 nodes are
+// single cells, all others are made of four quadrants one level down.
+type hlNode struct {
+	level int
+	quad
+	pop int64 // live cells
+}
+
+// quad holds the quadrants of a node. North is towards smaller y.
+type quad struct {
+	nw, ne, sw, se *hlNode
+}
+
+// hlResult identifies the centre of a node after 2^j generations
+type hlResult struct {
+	n *hlNode
+	j int
+}
+
+// NewHashLife returns a Hashlife engine for the rule, starting with the
+// live cells of the world
+func NewHashLife(rule Rule, world World) *HashLife {
+	h := &HashLife{rule: rule}
+	h.reset()
+	h.root = h.emptyNode(3)
+	for c, cell := range world {
+		if cell.Alive {
+			h.set(c)
+		}
+	}
+	return h
+}
+
+func (h *HashLife) reset() {
+	h.nodes = make(map[quad]*hlNode)
+	h.results = make(map[hlResult]*hlNode)
+	h.dead = &hlNode{}
+	h.alive = &hlNode{pop: 1}
+	h.empty = []*hlNode{h.dead}
+}
+
+// Generation is the number of generations computed so far
+func (h *HashLife) Generation() int64 {
+	return h.gen
+}
+
+// Population is the number of live cells of the current generation
+func (h *HashLife) Population() int64 {
+	return h.root.pop
+}
+
+// join returns the node made of the four quadrants, sharing it with all
+// equal nodes
+func (h *HashLife) join(nw, ne, sw, se *hlNode) *hlNode {
+	q := quad{nw, ne, sw, se}
+	if n, found := h.nodes[q]; found {
+		return n
+	}
+	n := &hlNode{level: nw.level + 1, quad: q, pop: nw.pop + ne.pop + sw.pop + se.pop}
+	h.nodes[q] = n
+	return n
+}
+
+// emptyNode returns the node of the level without live cells
+func (h *HashLife) emptyNode(level int) *hlNode {
+	for len(h.empty) <= level {
+		e := h.empty[len(h.empty)-1]
+		h.empty = append(h.empty, h.join(e, e, e, e))
+	}
+	return h.empty[level]
+}
+
+// half returns half the side of the root, the root covers -half to half-1
+// in both directions
+func (h *HashLife) half() int {
+	return 1 << (h.root.level - 1)
+}
+
+// set brings the cell at c to life, growing the root until it covers c
+func (h *HashLife) set(c Coord) {
+	for c.X < -h.half() || c.X >= h.half() || c.Y < -h.half() || c.Y >= h.half() {
+		h.root = h.expand(h.root)
+	}
+	h.root = h.setIn(h.root, c.X+h.half(), c.Y+h.half())
+}
+
+// setIn returns n with the cell at x, y within it alive
+func (h *HashLife) setIn(n *hlNode, x, y int) *hlNode {
+	if n.level == 0 {
+		return h.alive
+	}
+	half := 1 << (n.level - 1)
+	nw, ne, sw, se := n.nw, n.ne, n.sw, n.se
+	switch {
+	case x < half && y < half:
+		nw = h.setIn(nw, x, y)
+	case y < half:
+		ne = h.setIn(ne, x-half, y)
+	case x < half:
+		sw = h.setIn(sw, x, y-half)
+	default:
+		se = h.setIn(se, x-half, y-half)
+	}
+	return h.join(nw, ne, sw, se)
+}
+
+// expand returns a node one level up with n in its centre
+func (h *HashLife) expand(n *hlNode) *hlNode {
+	e := h.emptyNode(n.level - 1)
+	return h.join(
+		h.join(e, e, e, n.nw), h.join(e, e, n.ne, e),
+		h.join(e, n.sw, e, e), h.join(n.se, e, e, e))
+}
+
+// centre returns the node one level down in the centre of n
+func (h *HashLife) centre(n *hlNode) *hlNode {
+	return h.join(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw)
+}
+
+// Step advances the world by n generations
+func (h *HashLife) Step(n int64) {
+	for j := 0; n > 0; j, n = j+1, n>>1 {
+		if n&1 != 0 {
+			h.step(j)
+		}
+	}
+	if len(h.nodes) > hashLifeMaxNodes {
+		h.collect()
+	}
+}
+
+// step advances the world by 2^j generations. Before that the root grows
+// until all live cells are in its inner quarter and it is large enough
+// for 2^j generations, so nothing can grow out of the centre the result
+// covers.
+func (h *HashLife) step(j int) {
+	for h.root.level < j+3 || h.centre(h.centre(h.root)).pop != h.root.pop {
+		h.root = h.expand(h.root)
+	}
+	h.root = h.result(h.expand(h.root), j)
+	h.gen += 1 << j
+}
+
+// result returns the centre of n, one level down, after 2^j generations,
+// for j up to n.level-2
+func (h *HashLife) result(n *hlNode, j int) *hlNode {
+	if n.pop == 0 {
+		return h.emptyNode(n.level - 1)
+	}
+	key := hlResult{n, j}
+	if r, found := h.results[key]; found {
+		return r
+	}
+
+	var r *hlNode
+	if n.level == 2 {
+		r = h.evolve4x4(n)
+	} else {
+		// The nine overlapping squares of half the size of n
+		n00, n01, n02 := n.nw, h.join(n.nw.ne, n.ne.nw, n.nw.se, n.ne.sw), n.ne
+		n10 := h.join(n.nw.sw, n.nw.se, n.sw.nw, n.sw.ne)
+		n11 := h.centre(n)
+		n12 := h.join(n.ne.sw, n.ne.se, n.se.nw, n.se.ne)
+		n20, n21, n22 := n.sw, h.join(n.sw.ne, n.se.nw, n.sw.se, n.se.sw), n.se
+
+		if j == n.level-2 {
+			// Full speed: half the generations for the nine squares, the
+			// other half for the four squares made from them
+			k := j - 1
+			m := [9]*hlNode{
+				h.result(n00, k), h.result(n01, k), h.result(n02, k),
+				h.result(n10, k), h.result(n11, k), h.result(n12, k),
+				h.result(n20, k), h.result(n21, k), h.result(n22, k),
+			}
+			r = h.join(
+				h.result(h.join(m[0], m[1], m[3], m[4]), k),
+				h.result(h.join(m[1], m[2], m[4], m[5]), k),
+				h.result(h.join(m[3], m[4], m[6], m[7]), k),
+				h.result(h.join(m[4], m[5], m[7], m[8]), k))
+		} else {
+			// Slower: the nine squares stay as they are, all the
+			// generations are computed on the four squares
+			m := [9]*hlNode{
+				h.centre(n00), h.centre(n01), h.centre(n02),
+				h.centre(n10), h.centre(n11), h.centre(n12),
+				h.centre(n20), h.centre(n21), h.centre(n22),
+			}
+			r = h.join(
+				h.result(h.join(m[0], m[1], m[3], m[4]), j),
+				h.result(h.join(m[1], m[2], m[4], m[5]), j),
+				h.result(h.join(m[3], m[4], m[6], m[7]), j),
+				h.result(h.join(m[4], m[5], m[7], m[8]), j))
+		}
+	}
+
+	h.results[key] = r
+	return r
+}
+
+// evolve4x4 computes the next generation of the 2x2 centre of a 4x4 node
+func (h *HashLife) evolve4x4(n *hlNode) *hlNode {
+	var cells [4][4]bool
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			q := n.nw
+			switch {
+			case x >= 2 && y >= 2:
+				q = n.se
+			case x >= 2:
+				q = n.ne
+			case y >= 2:
+				q = n.sw
+			}
+			c := q.nw
+			switch {
+			case x%2 == 1 && y%2 == 1:
+				c = q.se
+			case x%2 == 1:
+				c = q.ne
+			case y%2 == 1:
+				c = q.sw
+			}
+			cells[y][x] = c.pop > 0
+		}
+	}
+
+	next := func(x, y int) *hlNode {
+		count := 0
+		for _, o := range neighbourhood {
+			if cells[y+o.Y][x+o.X] {
+				count++
+			}
+		}
+		alive := h.rule.Birth[count]
+		if cells[y][x] {
+			alive = h.rule.Survival[count]
+		}
+		if alive {
+			return h.alive
+		}
+		return h.dead
+	}
+	return h.join(next(1, 1), next(2, 1), next(1, 2), next(2, 2))
+}
+
+// collect drops all remembered results and the nodes the current
+// generation does not use
+func (h *HashLife) collect() {
+	old := h.root
+	h.reset()
+	var copyNode func(n *hlNode) *hlNode
+	copyNode = func(n *hlNode) *hlNode {
+		if n.level == 0 {
+			if n.pop > 0 {
+				return h.alive
+			}
+			return h.dead
+		}
+		if n.pop == 0 {
+			return h.emptyNode(n.level)
+		}
+		return h.join(copyNode(n.nw), copyNode(n.ne), copyNode(n.sw), copyNode(n.se))
+	}
+	h.root = copyNode(old)
+}
+
+// World returns the live cells of the current generation as a world
+func (h *HashLife) World() World {
+	world := make(World, h.root.pop)
+	var walk func(n *hlNode, x, y int)
+	walk = func(n *hlNode, x, y int) {
+		if n.pop == 0 {
+			return
+		}
+		if n.level == 0 {
+			world[Coord{x, y}] = Cell{true, 0, 0, 0}
+			return
+		}
+		half := 1 << (n.level - 1)
+		walk(n.nw, x, y)
+		walk(n.ne, x+half, y)
+		walk(n.sw, x, y+half)
+		walk(n.se, x+half, y+half)
+	}
+	walk(h.root, -h.half(), -h.half())
+	return world
+}
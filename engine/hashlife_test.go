@@ -0,0 +1,30 @@
+package engine
+
+import "testing"
+
+func TestHashLife(t *testing.T) {
+	// The R-pentomino stabilizes at generation 1103 with 116 cells
+	r := NewWorldFromCells(Coords(Coord{X: 1, Y: 0}, Coord{X: 2, Y: 0}, Coord{X: 0, Y: 1}, Coord{X: 1, Y: 1}, Coord{X: 1, Y: 2}))
+	h := NewHashLife(Conway, r)
+	h.Step(1103)
+	if n := h.Population(); n != 116 {
+		t.Errorf("r-pentomino population %d at generation 1103, want 116", n)
+	}
+
+	// A soup has to come out as from the map engine, in large and in
+	// small steps
+	world := make(World)
+	FillRandomSoup(world, 20150101, 128, 1)
+	large, small := NewHashLife(Conway, world), NewHashLife(Conway, world)
+	large.Step(100)
+	for i := 0; i < 100; i++ {
+		world = world.Tick()
+		small.Step(1)
+	}
+	if large.World().Hash() != world.Hash() {
+		t.Errorf("a soup stepped by 100 generations differs from the map engine")
+	}
+	if small.World().Hash() != world.Hash() {
+		t.Errorf("a soup stepped 100 times by one generation differs from the map engine")
+	}
+}
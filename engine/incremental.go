@@ -0,0 +1,128 @@
+package engine
+
+// Incremental computes the same generations as Engine, but keeps the
+// number of live neighbours of every cell from one generation to the next.
+// A cell can only change if it or one of its neighbours changed in the
+// generation before, so only the cells around the births and deaths of the
+// last generation are looked at, and only the counts around the new births
+// and deaths are updated. Worlds that are mostly still lifes and ash with a
+// few active spots are computed many times faster than by rebuilding the
+// whole world every generation.
+//
+// Like the map engine it assumes that nothing is born from nothing, so
+// rules with B0 are not computed correctly. Generations rules are not
+// supported.
+type Incremental struct {
+	rule  Rule
+	torus *Torus
+	gen   int
+
+	born    map[Coord]int // the live cells, with the generation they were born in
+	counts  map[Coord]int // the live neighbours of every cell that has any
+	changed []Coord       // the cells born or died in the last generation
+
+	// Reused from one generation to the next
+	visit map[Coord]struct{}
+	flips []Coord
+}
+
+// NewIncremental returns an incremental engine for the rule and topology
+// of e, starting with the live cells of the world. On a torus the world has
+// to be folded onto it already. The ages of the cells are kept.
+func NewIncremental(e Engine, world World) *Incremental {
+	in := &Incremental{
+		rule:   e.Rule,
+		torus:  e.Torus,
+		born:   make(map[Coord]int),
+		counts: make(map[Coord]int),
+		visit:  make(map[Coord]struct{}),
+	}
+	for c, cell := range world {
+		if cell.Alive {
+			in.born[c] = -cell.Age
+			in.changed = append(in.changed, c)
+			in.addNeighbours(c, 1)
+		}
+	}
+	return in
+}
+
+// neighbour returns the cell at c offset by dx, dy
+func (in *Incremental) neighbour(c Coord, dx, dy int) Coord {
+	n := Coord{c.X + dx, c.Y + dy}
+	if in.torus != nil {
+		n = in.torus.Wrap(n)
+	}
+	return n
+}
+
+// addNeighbours adds delta to the counts of the neighbours of c
+func (in *Incremental) addNeighbours(c Coord, delta int) {
+	for i := -1; i < 2; i++ {
+		for j := -1; j < 2; j++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+			n := in.neighbour(c, i, j)
+			if v := in.counts[n] + delta; v > 0 {
+				in.counts[n] = v
+			} else {
+				delete(in.counts, n)
+			}
+		}
+	}
+}
+
+// Step computes the next generation
+func (in *Incremental) Step() {
+	clear(in.visit)
+	for _, c := range in.changed {
+		for i := -1; i < 2; i++ {
+			for j := -1; j < 2; j++ {
+				in.visit[in.neighbour(c, i, j)] = struct{}{}
+			}
+		}
+	}
+
+	// All births and deaths are decided before any count changes
+	in.flips = in.flips[:0]
+	for c := range in.visit {
+		_, alive := in.born[c]
+		n := in.counts[c]
+		if alive && !in.rule.Survival[n] || !alive && n > 0 && in.rule.Birth[n] {
+			in.flips = append(in.flips, c)
+		}
+	}
+
+	in.gen++
+	for _, c := range in.flips {
+		if _, alive := in.born[c]; alive {
+			delete(in.born, c)
+			in.addNeighbours(c, -1)
+		} else {
+			in.born[c] = in.gen
+			in.addNeighbours(c, 1)
+		}
+	}
+	in.changed, in.flips = in.flips, in.changed
+}
+
+// Generation returns the number of generations computed
+func (in *Incremental) Generation() int {
+	return in.gen
+}
+
+// Population returns the number of live cells
+func (in *Incremental) Population() int {
+	return len(in.born)
+}
+
+// World returns the live cells of the current generation as a new world,
+// with their ages
+func (in *Incremental) World() World {
+	world := make(World, len(in.born))
+	for c, born := range in.born {
+		world[c] = Cell{true, 0, in.gen - born, 0}
+	}
+	return world
+}
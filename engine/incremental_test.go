@@ -0,0 +1,32 @@
+package engine
+
+import "testing"
+
+func TestIncremental(t *testing.T) {
+	// The incremental engine has to compute the same generations as the
+	// map engine, on the plane and on a torus, ages included
+	for _, torus := range []*Torus{nil, {Width: 64, Height: 48}} {
+		e := Engine{Rule: Conway, Torus: torus, Workers: 1}
+		world := make(World)
+		FillRandomSoup(world, 11, 80, 1)
+		if torus != nil {
+			world = torus.Fold(world)
+		}
+		in := NewIncremental(e, world)
+		for gen := 1; gen <= 300; gen++ {
+			world = e.Tick(world)
+			in.Step()
+		}
+		got := in.World()
+		if got.Hash() != world.Hash() {
+			t.Errorf("generation 300 of a soup differs from the map engine, torus %v", torus)
+			continue
+		}
+		for c, cell := range world {
+			if cell.Alive && got[c].Age != cell.Age {
+				t.Errorf("cell %d,%d is %d generations old, want %d", c.X, c.Y, got[c].Age, cell.Age)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// parallelMinCells is the smallest world Evolve spreads over several
+// workers. Below it starting the workers costs more than it saves.
+const parallelMinCells = 4096
+
+// shardBlock is the side of the square blocks of cells that are handed to
+// the workers as a whole. Most neighbours of a cell lie in the same block,
+// and so are counted by the same worker.
+const shardBlock = 64
+
+// shard returns the worker responsible for the cell at c
+func shard(c Coord, workers int) int {
+	h := uint64(FloorDiv(c.X, shardBlock))*0x9e3779b97f4a7c15 ^ uint64(FloorDiv(c.Y, shardBlock))
+	h ^= h >> 29
+	return int(h % uint64(workers))
+}
+
+// evolveParallel is Evolve spread over the workers of the engine. Every
+// worker owns the cells of some blocks of the world. In a first pass each
+// worker tells the owners of the neighbours of its live cells about them,
+// in a second pass each worker counts the neighbours of its cells and
+// applies the rule to them. The result does not depend on the number of
+// workers. If p is not nil the passes are timed as the phases of a tick:
+// telling the neighbours as Inflate, counting them as Count and applying
+// the rule and joining the shards as Apply.
+func (e Engine) evolveParallel(world World, p *PhaseTimings) World {
+	workers, wrap := e.Workers, e.wrap()
+
+	start := time.Now()
+	owned := make([][]Coord, workers)
+	for c := range world {
+		w := shard(c, workers)
+		owned[w] = append(owned[w], c)
+	}
+
+	// hits[w][v] are the neighbours of live cells of worker w owned by v,
+	// once for every live neighbour they have in w
+	hits := make([][][]Coord, workers)
+	forEachWorker(workers, func(w int) {
+		hits[w] = make([][]Coord, workers)
+		for _, c := range owned[w] {
+			if !world[c].Alive {
+				continue
+			}
+			for _, o := range neighbourhood {
+				n := Coord{c.X + o.X, c.Y + o.Y}
+				if wrap != nil {
+					n = wrap(n)
+				}
+				v := shard(n, workers)
+				hits[w][v] = append(hits[w][v], n)
+			}
+		}
+	})
+	if p != nil {
+		p.Inflate = time.Since(start)
+		start = time.Now()
+	}
+
+	shards := make([]World, workers)
+	forEachWorker(workers, func(v int) {
+		shard := make(World, len(owned[v]))
+		for _, c := range owned[v] {
+			shard[c] = Cell{world[c].Alive, 0, world[c].Age, world[c].State}
+		}
+		for w := range hits {
+			for _, n := range hits[w][v] {
+				cell := shard[n]
+				if _, found := shard[n]; !found {
+					cell = Cell{world[n].Alive, 0, world[n].Age, world[n].State}
+				}
+				cell.N++
+				shard[n] = cell
+			}
+		}
+		shards[v] = shard
+	})
+	if p != nil {
+		p.Count = time.Since(start)
+		start = time.Now()
+	}
+
+	forEachWorker(workers, func(v int) {
+		for c, cell := range shards[v] {
+			shards[v][c] = e.Rule.next(cell)
+		}
+	})
+
+	size := 0
+	for _, s := range shards {
+		size += len(s)
+	}
+	newWorld := make(World, size)
+	for _, s := range shards {
+		for c, cell := range s {
+			newWorld[c] = cell
+		}
+	}
+	if p != nil {
+		p.Apply = time.Since(start)
+	}
+	return newWorld
+}
+
+// forEachWorker runs f for every worker from 0 to n-1 at the same time and
+// waits for all of them
+func forEachWorker(n int, f func(w int)) {
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			f(w)
+		}(w)
+	}
+	wg.Wait()
+}
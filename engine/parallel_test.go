@@ -0,0 +1,21 @@
+package engine
+
+import "testing"
+
+func TestParallelGenerations(t *testing.T) {
+	// The cells of a Star Wars soup decay alike with one and four workers
+	starWars, err := ParseRule("345/2/4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	soup := make(World)
+	FillRandomSoup(soup, 20150101, 200, 1)
+	a, b := soup, soup
+	for i := 0; i < 50; i++ {
+		a = Engine{Rule: starWars}.Tick(a)
+		b = Engine{Rule: starWars, Workers: 4}.Tick(b)
+	}
+	if a.Hash() != b.Hash() {
+		t.Errorf("a Star Wars soup differs between one and four workers after 50 generations")
+	}
+}
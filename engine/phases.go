@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+)
+
+// PhaseTimings holds the time spent in each phase of Tick
+type PhaseTimings struct {
+	Inflate time.Duration // surrounding the live cells with dead ones
+	Count   time.Duration // counting the live neighbours
+	Apply   time.Duration // applying the rules
+	Deflate time.Duration // dropping dead cells by the prune policy
+}
+
+// Total is the time spent in all phases together
+func (p PhaseTimings) Total() time.Duration {
+	return p.Inflate + p.Count + p.Apply + p.Deflate
+}
+
+// Add adds the timings of q to p
+func (p *PhaseTimings) Add(q PhaseTimings) {
+	p.Inflate += q.Inflate
+	p.Count += q.Count
+	p.Apply += q.Apply
+	p.Deflate += q.Deflate
+}
+
+// String reports the time and share of every phase
+func (p PhaseTimings) String() string {
+	total := p.Total()
+	share := func(d time.Duration) float64 {
+		if total == 0 {
+			return 0
+		}
+		return 100 * float64(d) / float64(total)
+	}
+	return fmt.Sprintf("inflate %s (%.0f%%), count %s (%.0f%%), apply %s (%.0f%%), deflate %s (%.0f%%)",
+		p.Inflate, share(p.Inflate), p.Count, share(p.Count), p.Apply, share(p.Apply), p.Deflate, share(p.Deflate))
+}
+
+// TickTimed computes generation gen from the world, pruning it with the
+// given policy, and returns the time spent in each phase
+func (world World) TickTimed(prune PrunePolicy, gen int) (World, PhaseTimings) {
+	return Engine{Rule: Conway}.TickTimed(world, prune, gen)
+}
+
+// TickTimed is World.TickTimed for the rule and topology of the engine
+func (e Engine) TickTimed(world World, prune PrunePolicy, gen int) (World, PhaseTimings) {
+	var p PhaseTimings
+	if e.parallel(world) {
+		world = e.evolveParallel(world, &p)
+		start := time.Now()
+		world = prune.Prune(world, gen)
+		p.Deflate = time.Since(start)
+		return world, p
+	}
+	wrap := e.wrap()
+
+	start := time.Now()
+	world = world.inflate(wrap)
+	p.Inflate = time.Since(start)
+
+	start = time.Now()
+	world = world.countLiveNeighbours(wrap)
+	p.Count = time.Since(start)
+
+	start = time.Now()
+	world = world.ApplyRule(e.Rule)
+	p.Apply = time.Since(start)
+
+	start = time.Now()
+	world = prune.Prune(world, gen)
+	p.Deflate = time.Since(start)
+
+	return world, p
+}
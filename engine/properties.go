@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// symmetries are the eight symmetries of the square grid, the rotations
+// by multiples of 90 degrees with and without a flip
+var symmetries = []struct {
+	name string
+	f    func(Coord) Coord
+}{
+	{"identity", func(c Coord) Coord { return c }},
+	{"rotation by 90", func(c Coord) Coord { return Coord{-c.Y, c.X} }},
+	{"rotation by 180", func(c Coord) Coord { return Coord{-c.X, -c.Y} }},
+	{"rotation by 270", func(c Coord) Coord { return Coord{c.Y, -c.X} }},
+	{"flip in x", func(c Coord) Coord { return Coord{-c.X, c.Y} }},
+	{"flip in y", func(c Coord) Coord { return Coord{c.X, -c.Y} }},
+	{"flip on the diagonal", func(c Coord) Coord { return Coord{c.Y, c.X} }},
+	{"flip on the antidiagonal", func(c Coord) Coord { return Coord{-c.Y, -c.X} }},
+}
+
+// CheckProperties checks properties every correct engine has on random
+// worlds drawn from the seed: the empty world stays empty, Inflate
+// followed by Deflate leaves the live cells alone, and a tick commutes
+// with moving the world and, on the plane, with the symmetries of the
+// grid, which life-like rules do not tell apart. It returns a description
+// of every violation found in the given number of trials.
+func CheckProperties(e Engine, seed int64, trials int) []string {
+	return checkProperties(e, e.Tick, seed, trials)
+}
+
+// checkProperties is CheckProperties for the generations computed by tick,
+// which may be any of the engines for the rule and torus of e
+func checkProperties(e Engine, tick func(World) World, seed int64, trials int) []string {
+	var violations []string
+	fail := func(trial int, format string, args ...interface{}) {
+		violations = append(violations, fmt.Sprintf("trial %d: ", trial)+fmt.Sprintf(format, args...))
+	}
+	move := func(world World, f func(Coord) Coord) World {
+		moved := make(World, len(world))
+		for c, cell := range world {
+			if cell.Alive {
+				moved[f(c)] = Cell{true, 0, 0, 0}
+			}
+		}
+		if e.Torus != nil {
+			moved = e.Torus.Fold(moved)
+		}
+		return moved
+	}
+
+	if n := len(tick(World{})); n != 0 {
+		fail(0, "the empty world has %d cells after a tick", n)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	for trial := 1; trial <= trials; trial++ {
+		// Worlds from a few cells up to more than the engine needs to
+		// bring in its workers
+		size := 4 + rng.Intn(125)
+		density := 0.1 + 0.4*rng.Float64()
+		world := make(World)
+		for y := -size / 2; y < size-size/2; y++ {
+			for x := -size / 2; x < size-size/2; x++ {
+				if rng.Float64() < density {
+					world[Coord{x, y}] = Cell{true, 0, 0, 0}
+				}
+			}
+		}
+		if e.Torus != nil {
+			world = e.Torus.Fold(world)
+		}
+
+		if d := Hamming(world.Inflate().Deflate(), world); d != 0 {
+			fail(trial, "Inflate and Deflate change %d cells", d)
+		}
+
+		next := tick(world)
+
+		shift := Coord{rng.Intn(201) - 100, rng.Intn(201) - 100}
+		translate := func(c Coord) Coord { return Coord{c.X + shift.X, c.Y + shift.Y} }
+		if d := Hamming(tick(move(world, translate)), move(next, translate)); d != 0 {
+			fail(trial, "moving the world by %d,%d changes %d cells of the next generation", shift.X, shift.Y, d)
+		}
+
+		if e.Torus != nil {
+			continue
+		}
+		for _, s := range symmetries {
+			if d := Hamming(tick(move(world, s.f)), move(next, s.f)); d != 0 {
+				fail(trial, "%s of the world changes %d cells of the next generation", s.name, d)
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,56 @@
+package engine
+
+import "testing"
+
+func TestProperties(t *testing.T) {
+	torus := Torus{Width: 96, Height: 64}
+	highLife := Rule{Birth: [9]bool{3: true, 6: true}, Survival: Conway.Survival}
+
+	// The engines keeping the world in their own form compute one
+	// generation of a world handed to them
+	incremental := func(e Engine) func(World) World {
+		return func(world World) World {
+			in := NewIncremental(e, world)
+			in.Step()
+			return in.World()
+		}
+	}
+	dense := func(e Engine) func(World) World {
+		return func(world World) World {
+			d := NewDense(e, world)
+			d.Step()
+			return d.World()
+		}
+	}
+	hashlife := func(e Engine) func(World) World {
+		return func(world World) World {
+			h := NewHashLife(e.Rule, world)
+			h.Step(1)
+			return h.World()
+		}
+	}
+	mapEngine := func(e Engine) func(World) World { return e.Tick }
+
+	engines := []struct {
+		name   string
+		engine Engine
+		tick   func(Engine) func(World) World
+	}{
+		{"map", Engine{Rule: Conway}, mapEngine},
+		{"parallel map", Engine{Rule: Conway, Workers: 4}, mapEngine},
+		{"torus", Engine{Rule: Conway, Torus: &torus}, mapEngine},
+		{"HighLife", Engine{Rule: highLife}, mapEngine},
+		{"incremental", Engine{Rule: Conway}, incremental},
+		{"incremental torus", Engine{Rule: Conway, Torus: &torus}, incremental},
+		{"incremental HighLife", Engine{Rule: highLife}, incremental},
+		{"dense", Engine{Rule: Conway, Torus: &torus, Workers: 4}, dense},
+		{"dense HighLife", Engine{Rule: highLife, Torus: &torus}, dense},
+		{"hashlife", Engine{Rule: Conway}, hashlife},
+		{"hashlife HighLife", Engine{Rule: highLife}, hashlife},
+	}
+	for _, e := range engines {
+		for _, v := range checkProperties(e.engine, e.tick(e.engine), 20150101, 20) {
+			t.Errorf("%s engine: %s", e.name, v)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A PrunePolicy decides which dead cells are dropped from the world after
+// a generation has been computed. Dropping them keeps the maps small,
+// keeping them saves Inflate from recreating them in the next tick.
+type PrunePolicy struct {
+	Every int  // drop all dead cells every Every generations
+	Halo  bool // keep the dead cells next to live cells, drop the rest
+}
+
+// PruneAlways drops all dead cells after every generation, which is what
+// Tick does
+var PruneAlways = PrunePolicy{Every: 1}
+
+// Prune drops the dead cells of the world after generation gen
+func (p PrunePolicy) Prune(world World, gen int) World {
+	if p.Halo {
+		return world.Trim()
+	}
+	if p.Every > 0 && gen%p.Every == 0 {
+		return world.Deflate()
+	}
+	return world
+}
+
+func (p PrunePolicy) String() string {
+	switch {
+	case p.Halo:
+		return "halo"
+	case p.Every == 1:
+		return "always"
+	default:
+		return fmt.Sprintf("every=%d", p.Every)
+	}
+}
+
+// ParsePrunePolicy parses the names used by String
+func ParsePrunePolicy(s string) (PrunePolicy, error) {
+	switch {
+	case s == "always":
+		return PruneAlways, nil
+	case s == "halo":
+		return PrunePolicy{Halo: true}, nil
+	case strings.HasPrefix(s, "every="):
+		k, err := strconv.Atoi(strings.TrimPrefix(s, "every="))
+		if err != nil || k < 1 {
+			return PrunePolicy{}, fmt.Errorf("invalid prune interval in %q", s)
+		}
+		return PrunePolicy{Every: k}, nil
+	}
+	return PrunePolicy{}, fmt.Errorf("unknown prune policy %q", s)
+}
+
+// Trim drops the dead cells of the world that have no live neighbour, so
+// only the live and decaying cells and the halo around the live ones
+// remain
+func (world World) Trim() World {
+	newWorld := make(World)
+
+	for coord, cell := range world {
+		if cell.Alive || cell.State > 0 {
+			newWorld[coord] = cell
+			continue
+		}
+		for _, offset := range neighbourhood {
+			if world[Coord{coord.X + offset.X, coord.Y + offset.Y}].Alive {
+				newWorld[coord] = cell
+				break
+			}
+		}
+	}
+
+	return newWorld
+}
@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"fmt"
+)
+
+// A Region is a rectangle of cells, including its corners
+type Region struct {
+	Min, Max Coord
+}
+
+// Contains tells if the cell at c lies within the region
+func (r Region) Contains(c Coord) bool {
+	return r.Min.X <= c.X && c.X <= r.Max.X && r.Min.Y <= c.Y && c.Y <= r.Max.Y
+}
+
+func (r Region) String() string {
+	return fmt.Sprintf("%d,%d:%d,%d", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y)
+}
+
+// ParseRegion parses a region written as x0,y0:x1,y1, the format of
+// String. The corners may be given in any order.
+func ParseRegion(s string) (Region, error) {
+	var r Region
+	if _, err := fmt.Sscanf(s, "%d,%d:%d,%d", &r.Min.X, &r.Min.Y, &r.Max.X, &r.Max.Y); err != nil {
+		return r, fmt.Errorf("invalid region %q, expected x0,y0:x1,y1", s)
+	}
+	if r.Min.X > r.Max.X {
+		r.Min.X, r.Max.X = r.Max.X, r.Min.X
+	}
+	if r.Min.Y > r.Max.Y {
+		r.Min.Y, r.Max.Y = r.Max.Y, r.Min.Y
+	}
+	return r, nil
+}
+
+// Clip drops all cells of the world outside the region. Clipping after
+// every tick treats everything outside the region as permanently dead:
+// nothing is born there and escaping patterns vanish at the border.
+// The world is changed in place and returned for convenience.
+func (world World) Clip(r Region) World {
+	for coord := range world {
+		if !r.Contains(coord) {
+			delete(world, coord)
+		}
+	}
+	return world
+}
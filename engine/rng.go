@@ -0,0 +1,120 @@
+package engine
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/bits"
+	"math/rand"
+)
+
+// An RNG generates the random numbers random soups and ash fields are
+// made of. Int63 returns a non-negative number, as the Int63 of a
+// math/rand Source does. Split returns the n-th generator derived from the
+// RNG, independent of the RNG itself and of the other derived generators;
+// FillSoup gives one to every strip of a soup, so the soup only depends on
+// the RNG and not on the number of workers.
+type RNG interface {
+	Int63() int64
+	Split(n uint64) RNG
+}
+
+// NewRand returns a math/rand generator drawing its numbers from rng
+func NewRand(rng RNG) *rand.Rand {
+	return rand.New(randSource{rng})
+}
+
+// randSource makes an RNG a math/rand Source
+type randSource struct {
+	RNG
+}
+
+func (randSource) Seed(int64) {}
+
+// NewMathRNG returns an RNG using the generator of math/rand, seeded with
+// seed. It is the one FillRandomSoup and FillAshField use.
+func NewMathRNG(seed int64) RNG {
+	return mathRNG{rand.NewSource(seed), seed}
+}
+
+type mathRNG struct {
+	rand.Source
+	seed int64
+}
+
+func (r mathRNG) Split(n uint64) RNG {
+	return NewMathRNG(splitSeed(r.seed, n))
+}
+
+// NewXoshiroRNG returns an xoshiro256** generator seeded with seed. It is
+// a lot faster than math/rand, and splitting it costs next to nothing.
+func NewXoshiroRNG(seed int64) RNG {
+	r := &xoshiroRNG{seed: seed}
+	for i := range r.s {
+		r.s[i] = uint64(splitSeed(seed, uint64(i)))
+	}
+	return r
+}
+
+type xoshiroRNG struct {
+	s    [4]uint64
+	seed int64
+}
+
+func (r *xoshiroRNG) Uint64() uint64 {
+	s := &r.s
+	result := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+	return result
+}
+
+func (r *xoshiroRNG) Int63() int64 {
+	return int64(r.Uint64() >> 1)
+}
+
+func (r *xoshiroRNG) Split(n uint64) RNG {
+	return NewXoshiroRNG(splitSeed(r.seed, n))
+}
+
+// NewSequenceRNG returns an RNG handing out the given numbers in turn,
+// starting over after the last one, for checking code that depends on
+// random numbers against known inputs. The numbers must not be negative.
+// Every generator split from it starts at the first number again.
+func NewSequenceRNG(values ...int64) RNG {
+	return &sequenceRNG{values: values}
+}
+
+type sequenceRNG struct {
+	values []int64
+	next   int
+}
+
+func (r *sequenceRNG) Int63() int64 {
+	v := r.values[r.next]
+	r.next = (r.next + 1) % len(r.values)
+	return v
+}
+
+func (r *sequenceRNG) Split(uint64) RNG {
+	return NewSequenceRNG(r.values...)
+}
+
+// CryptoSeed returns a seed read from the random source of the operating
+// system, for runs that are not asked to repeat an earlier one. The seed
+// is never 0.
+func CryptoSeed() int64 {
+	var b [8]byte
+	for {
+		if _, err := crand.Read(b[:]); err != nil {
+			panic(err)
+		}
+		if seed := int64(binary.LittleEndian.Uint64(b[:]) >> 1); seed != 0 {
+			return seed
+		}
+	}
+}
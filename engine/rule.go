@@ -0,0 +1,193 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A Rule declares a life-like rule by the numbers of live neighbours
+// for which a dead cell is born and a live cell survives. A Generations
+// rule has more than two states: a live cell that does not survive goes
+// through States-2 decay states, one per generation, before it is dead.
+// Decaying cells do not count as live neighbours and cannot be born.
+type Rule struct {
+	Birth    [9]bool
+	Survival [9]bool
+	States   int // of a Generations rule, 0 for a life-like rule
+}
+
+// MaxStates is the largest number of states of a Generations rule, so
+// that the decay states fit into Cell.State
+const MaxStates = 256
+
+// Conway is B3/S23, the rule of Conway's Game of Life
+var Conway = Rule{
+	Birth:    [9]bool{3: true},
+	Survival: [9]bool{2: true, 3: true},
+}
+
+// String returns the rule in B/S notation, e.g. B3/S23, or a Generations
+// rule in the S/B/C notation of Golly, e.g. 345/2/4
+func (r Rule) String() string {
+	if r.States > 0 {
+		s := ""
+		for n, survives := range r.Survival {
+			if survives {
+				s += strconv.Itoa(n)
+			}
+		}
+		s += "/"
+		for n, born := range r.Birth {
+			if born {
+				s += strconv.Itoa(n)
+			}
+		}
+		return s + "/" + strconv.Itoa(r.States)
+	}
+	s := "B"
+	for n, born := range r.Birth {
+		if born {
+			s += strconv.Itoa(n)
+		}
+	}
+	s += "/S"
+	for n, survives := range r.Survival {
+		if survives {
+			s += strconv.Itoa(n)
+		}
+	}
+	return s
+}
+
+// ParseRule parses a life-like rule in B/S notation, e.g. B36/S23 for
+// HighLife, in any case and with the parts in either order. The older
+// S/B notation of plain digits, e.g. 23/36, is understood as well. A third
+// part makes a Generations rule with that number of states, e.g. 345/2/4
+// or B2/S345/C4; two states are a life-like rule.
+func ParseRule(s string) (Rule, error) {
+	var r Rule
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(s)), "/")
+	if len(parts) == 3 {
+		// The number of states is last in S/B/C notation, or marked by C
+		// or G anywhere
+		i := slices.IndexFunc(parts, func(p string) bool {
+			return strings.HasPrefix(p, "C") || strings.HasPrefix(p, "G")
+		})
+		if i < 0 {
+			i = 2
+		}
+		n, err := strconv.Atoi(strings.TrimLeft(parts[i], "CG"))
+		if err != nil || n < 2 || n > MaxStates {
+			return r, fmt.Errorf("invalid number of states %q in rule %q, expected 2 to %d", parts[i], s, MaxStates)
+		}
+		if n > 2 {
+			r.States = n
+		}
+		parts = slices.Delete(parts, i, i+1)
+	}
+	if len(parts) != 2 {
+		return r, fmt.Errorf("invalid rule %q, expected Bxx/Sxx", s)
+	}
+	first, second := parts[0], parts[1]
+
+	// Without letters the survival digits come first
+	if !strings.HasPrefix(first, "B") && !strings.HasPrefix(first, "S") {
+		first, second = "S"+first, "B"+second
+	}
+	for _, part := range []string{first, second} {
+		var set *[9]bool
+		switch {
+		case strings.HasPrefix(part, "B"):
+			set = &r.Birth
+		case strings.HasPrefix(part, "S"):
+			set = &r.Survival
+		default:
+			return r, fmt.Errorf("invalid rule %q, expected Bxx/Sxx", s)
+		}
+		for _, d := range part[1:] {
+			if d < '0' || d > '8' {
+				return r, fmt.Errorf("invalid neighbour count %q in rule %q", d, s)
+			}
+			set[d-'0'] = true
+		}
+	}
+	if first[0] == second[0] {
+		return r, fmt.Errorf("invalid rule %q, expected Bxx/Sxx", s)
+	}
+	return r, nil
+}
+
+// next returns the cell in the next generation, from the cell with its
+// live neighbours counted
+func (r Rule) next(cell Cell) Cell {
+	if cell.Alive {
+		if r.Survival[cell.N] {
+			return Cell{true, 0, cell.Age + 1, 0}
+		}
+		if r.States > 2 {
+			return Cell{false, 0, 0, 1}
+		}
+		return Cell{false, 0, 0, 0}
+	}
+	if cell.State > 0 {
+		if int(cell.State) < r.States-2 {
+			return Cell{false, 0, 0, cell.State + 1}
+		}
+		return Cell{false, 0, 0, 0}
+	}
+	return Cell{r.Birth[cell.N], 0, 0, 0}
+}
+
+// neighbourhood lists the offsets of the eight neighbours of a cell
+var neighbourhood = [8]Coord{
+	{-1, -1}, {0, -1}, {1, -1},
+	{-1, 0}, {1, 0},
+	{-1, 1}, {0, 1}, {1, 1},
+}
+
+// VerifyRules runs the engine for the rule on every one of the 512
+// configurations of a cell and its eight neighbours and checks the fate
+// of the centre cell against the rule. It returns a description of every
+// configuration where the engine disagrees with the rule.
+func VerifyRules(rule Rule) []string {
+	var mismatches []string
+	engine := Engine{Rule: rule}
+
+	for config := 0; config < 512; config++ {
+		world := make(World)
+		alive := config&1 != 0
+		if alive {
+			world[Coord{0, 0}] = Cell{true, 0, 0, 0}
+		}
+		n := 0
+		for i, offset := range neighbourhood {
+			if config&(2<<i) != 0 {
+				world[offset] = Cell{true, 0, 0, 0}
+				n++
+			}
+		}
+
+		want := rule.Birth[n]
+		if alive {
+			want = rule.Survival[n]
+		}
+		got := engine.Tick(world)[Coord{0, 0}].Alive
+
+		if got != want {
+			mismatches = append(mismatches, fmt.Sprintf(
+				"configuration %03x: %s cell with %d live neighbours should be %s, engine says %s",
+				config, state(alive), n, state(want), state(got)))
+		}
+	}
+
+	return mismatches
+}
+
+func state(alive bool) string {
+	if alive {
+		return "live"
+	}
+	return "dead"
+}
@@ -0,0 +1,65 @@
+package engine
+
+import "testing"
+
+func TestVerifyRules(t *testing.T) {
+	for _, s := range []string{"B3/S23", "B36/S23", "B2/S", "B3678/S34678"} {
+		r, err := ParseRule(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m := VerifyRules(r); len(m) > 0 {
+			t.Errorf("%s: %d of 512 neighbourhoods wrong, first: %s", s, len(m), m[0])
+		}
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"B3/S23", "B3/S23"},
+		{"b36/s23", "B36/S23"},
+		{"S23/B3", "B3/S23"},
+		{"23/3", "B3/S23"},
+		{"B3/S23/C2", "B3/S23"},
+		{"345/2/4", "345/2/4"},
+		{"B2/S345/C4", "345/2/4"},
+		{"/2/3", "/2/3"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRule(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if s := r.String(); s != tt.want {
+			t.Errorf("%s reads back as %s, want %s", tt.in, s, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "B3", "B3/B3", "B9/S23", "Bx/S23", "B3/S23/1", "B3/S23/C257", "B3/S23/4/5"} {
+		if r, err := ParseRule(in); err == nil {
+			t.Errorf("%q reads as %s, want an error", in, r)
+		}
+	}
+}
+
+func TestGenerations(t *testing.T) {
+	// The spaceship of Brian's Brain, two live cells followed by two
+	// decaying ones, moves by a cell every generation
+	brain, err := ParseRule("/2/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := Engine{Rule: brain}
+	world := World{
+		{X: 0, Y: 0}: {Alive: true}, {X: 0, Y: 1}: {Alive: true},
+		{X: -1, Y: 0}: {State: 1}, {X: -1, Y: 1}: {State: 1},
+	}
+	for i := 1; i <= 10; i++ {
+		world = e.Tick(world)
+		cells := world.LiveCells()
+		if len(world) != 4 || len(cells) != 2 || cells[0] != (Coord{X: i, Y: 0}) || cells[1] != (Coord{X: i, Y: 1}) {
+			t.Fatalf("Brian's Brain spaceship is %v in generation %d", cells, i)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package engine
+
+// A CellSeq hands out coordinates one after the other, stopping early if
+// yield returns false. It has the shape of an iter.Seq, so a CellSeq can
+// be written as a range-over-func iterator.
+type CellSeq func(yield func(Coord) bool)
+
+// Coords returns the sequence of the given coordinates
+func Coords(coords ...Coord) CellSeq {
+	return func(yield func(Coord) bool) {
+		for _, c := range coords {
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// NewWorldFromCells returns a new world with a live cell at every
+// coordinate of the sequence
+func NewWorldFromCells(cells CellSeq) World {
+	world := make(World)
+	cells(func(c Coord) bool {
+		world[c] = Cell{true, 0, 0, 0}
+		return true
+	})
+	return world
+}
+
+// Cells returns the sequence of the live cells of the world, in the order
+// of LiveCells
+func (world World) Cells() CellSeq {
+	return Coords(world.LiveCells()...)
+}
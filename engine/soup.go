@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// soupStrip is the number of columns of a random soup generated from one
+// random number generator. The strips, not the workers, decide which
+// generator fills which cell, so a soup only depends on its seed and size
+// and not on the number of processors.
+const soupStrip = 64
+
+// FillRandomSoup fills a size x size square of the world centred on the
+// origin with live cells, each alive with a probability of 20%. It is
+// FillSoup with the math/rand generator seeded with seed.
+func FillRandomSoup(world World, seed int64, size int, workers int) {
+	FillSoup(world, NewMathRNG(seed), size, workers)
+}
+
+// FillSoup fills a size x size square of the world centred on the origin
+// with live cells, each alive with a probability of 20%. The strips of the
+// soup are generated in parallel by the given number of workers, each
+// strip from its own generator split from rng, and streamed into the world
+// as they are done, so only a few strips are ever held in memory next to
+// the world itself.
+func FillSoup(world World, rng RNG, size int, workers int) {
+	fillSoup(world, rng, size, workers, 0.2)
+}
+
+// FillSoupDensity is FillSoup with cells alive with the given probability
+// instead of 20%
+func FillSoupDensity(world World, rng RNG, size int, workers int, density float64) {
+	fillSoup(world, rng, size, workers, density)
+}
+
+// soupPercent converts a probability into percent, and tells whether it
+// is a whole number of percent. Those soups are drawn in whole percent, as
+// before there were others, so that they stay the same for the same seed.
+func soupPercent(density float64) (int, bool) {
+	p := math.Round(density * 100)
+	return int(p), math.Abs(density*100-p) < 1e-9
+}
+
+// NewRandomWorld returns a random soup of size x size cells centred on the
+// origin, each alive with the given probability. The soup only depends on
+// the arguments: with a density of 0.2 it is the one FillRandomSoup fills
+// in for the same seed.
+func NewRandomWorld(seed int64, size int, density float64) World {
+	world := make(World)
+	fillSoup(world, NewMathRNG(seed), size, runtime.NumCPU(), density)
+	return world
+}
+
+// fillSoup fills the soup with cells alive with a probability of density
+func fillSoup(world World, rng RNG, size int, workers int, density float64) {
+	strips := (size + soupStrip - 1) / soupStrip
+
+	next := make(chan int)
+	done := make(chan []Coord, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range next {
+				done <- soupStripCells(rng.Split(uint64(s)), s, size, density)
+			}
+		}()
+	}
+	go func() {
+		for s := 0; s < strips; s++ {
+			next <- s
+		}
+		close(next)
+		wg.Wait()
+		close(done)
+	}()
+
+	for cells := range done {
+		for _, c := range cells {
+			world[c] = Cell{true, 0, 0, 0}
+		}
+	}
+}
+
+// soupStripCells generates the live cells of strip s of a random soup
+func soupStripCells(src RNG, s int, size int, density float64) []Coord {
+	rng := NewRand(src)
+	percent, whole := soupPercent(density)
+	alive := func() bool { return rng.Float64() < density }
+	if whole {
+		alive = func() bool { return rng.Intn(100) < percent }
+	}
+
+	var cells []Coord
+	for i := s * soupStrip; i < (s+1)*soupStrip && i < size; i++ {
+		for j := 0; j < size; j++ {
+			if alive() {
+				cells = append(cells, Coord{i - size/2, j - size/2})
+			}
+		}
+	}
+	return cells
+}
+
+// splitSeed derives the seed of the n-th independent generator from a
+// seed, using the SplitMix64 finalizer so that neighbouring strips get
+// unrelated sequences
+func splitSeed(seed int64, n uint64) int64 {
+	z := uint64(seed) + (n+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// An ashObject is a small still life or oscillator used to build ash
+// fields. The cells of all its phases fit into a w x h box.
+type ashObject struct {
+	name  string
+	w, h  int
+	cells []Coord
+}
+
+var ashObjects = []ashObject{
+	{"block", 2, 2, []Coord{{0, 0}, {1, 0}, {0, 1}, {1, 1}}},
+	{"beehive", 4, 3, []Coord{{1, 0}, {2, 0}, {0, 1}, {3, 1}, {1, 2}, {2, 2}}},
+	{"loaf", 4, 4, []Coord{{1, 0}, {2, 0}, {0, 1}, {3, 1}, {1, 2}, {3, 2}, {2, 3}}},
+	{"boat", 3, 3, []Coord{{0, 0}, {1, 0}, {0, 1}, {2, 1}, {1, 2}}},
+	{"ship", 3, 3, []Coord{{0, 0}, {1, 0}, {0, 1}, {2, 1}, {1, 2}, {2, 2}}},
+	{"tub", 3, 3, []Coord{{1, 0}, {0, 1}, {2, 1}, {1, 2}}},
+	{"pond", 4, 4, []Coord{{1, 0}, {2, 0}, {0, 1}, {3, 1}, {0, 2}, {3, 2}, {1, 3}, {2, 3}}},
+	{"blinker", 3, 3, []Coord{{1, 0}, {1, 1}, {1, 2}}},
+}
+
+// ashSlot is the size of the square each ash object is placed in. With
+// objects of at most 4 x 4 cells there are always two dead cells between
+// objects, so they cannot disturb each other.
+const ashSlot = 7
+
+// FillAshField scatters randomly chosen, rotated and mirrored ash objects
+// over a size x size square of the world centred on the origin. It is
+// FillAsh with the math/rand generator seeded with seed.
+func FillAshField(world World, seed int64, size int, density float64) {
+	FillAsh(world, NewMathRNG(seed), size, density)
+}
+
+// FillAsh scatters randomly chosen, rotated and mirrored ash objects over
+// a size x size square of the world centred on the origin. The square is
+// cut into slots of ashSlot x ashSlot cells and every slot holds an object
+// with the given probability.
+func FillAsh(world World, src RNG, size int, density float64) {
+	rng := NewRand(src)
+	slots := size / ashSlot
+
+	for sx := 0; sx < slots; sx++ {
+		for sy := 0; sy < slots; sy++ {
+			if rng.Float64() >= density {
+				continue
+			}
+			obj := ashObjects[rng.Intn(len(ashObjects))]
+			cells, w, h := obj.cells, obj.w, obj.h
+			for r := rng.Intn(4); r > 0; r-- {
+				cells, w, h = rotateInBox(cells, w, h)
+			}
+			if rng.Intn(2) == 1 {
+				cells = flipInBox(cells, w)
+			}
+
+			ox := sx*ashSlot - size/2 + rng.Intn(ashSlot-1-w)
+			oy := sy*ashSlot - size/2 + rng.Intn(ashSlot-1-h)
+			for _, c := range cells {
+				world[Coord{ox + c.X, oy + c.Y}] = Cell{true, 0, 0, 0}
+			}
+		}
+	}
+}
+
+// rotateInBox rotates cells in a w x h box by 90 degrees, giving cells in
+// an h x w box
+func rotateInBox(cells []Coord, w, h int) ([]Coord, int, int) {
+	rotated := make([]Coord, len(cells))
+	for i, c := range cells {
+		rotated[i] = Coord{h - 1 - c.Y, c.X}
+	}
+	return rotated, h, w
+}
+
+// flipInBox mirrors cells in a box of width w
+func flipInBox(cells []Coord, w int) []Coord {
+	flipped := make([]Coord, len(cells))
+	for i, c := range cells {
+		flipped[i] = Coord{w - 1 - c.X, c.Y}
+	}
+	return flipped
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"fmt"
+)
+
+// A Torus is a bounded world of Width x Height cells whose opposite edges
+// are joined, so a glider leaving on the right comes back on the left.
+// Its cells run from -Width/2 to Width-Width/2-1 in x, and likewise in y,
+// so that it is centred on the origin like the view.
+type Torus struct {
+	Width, Height int
+}
+
+func (t Torus) String() string {
+	return fmt.Sprintf("%dx%d torus", t.Width, t.Height)
+}
+
+// Wrap returns the cell of the torus at c, taking the coordinates modulo
+// the size of the torus
+func (t Torus) Wrap(c Coord) Coord {
+	return Coord{wrap(c.X, t.Width), wrap(c.Y, t.Height)}
+}
+
+// wrap maps v into [-n/2, n-n/2)
+func wrap(v, n int) int {
+	h := n / 2
+	v = (v + h) % n
+	if v < 0 {
+		v += n
+	}
+	return v - h
+}
+
+// FloorDiv divides a by b rounding towards negative infinity, so that the
+// blocks of b cells left and below of the origin have the same size as all
+// the others
+func FloorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// Fold returns the world with every cell moved onto the torus. Cells that
+// end up in the same place are alive if one of them was.
+func (t Torus) Fold(world World) World {
+	newWorld := make(World, len(world))
+	for coord, cell := range world {
+		c := t.Wrap(coord)
+		if cell.Alive || !newWorld[c].Alive {
+			newWorld[c] = Cell{cell.Alive, 0, cell.Age, cell.State}
+		}
+	}
+	return newWorld
+}
+
+// Evolve computes the next generation of a world on the torus, keeping
+// the dead cells around the live ones. The world has to be folded onto
+// the torus already.
+func (t Torus) Evolve(world World) World {
+	return Engine{Rule: Conway, Torus: &t}.Evolve(world)
+}
+
+// Tick computes the next generation of live cells of a world on the torus
+func (t Torus) Tick(world World) World {
+	return Engine{Rule: Conway, Torus: &t}.Tick(world)
+}
@@ -0,0 +1,32 @@
+package engine
+
+import "testing"
+
+func TestFloorDiv(t *testing.T) {
+	tests := []struct{ a, b, want int }{
+		{7, 2, 3}, {-7, 2, -4}, {7, -2, -4}, {-7, -2, 3},
+		{6, 2, 3}, {-6, 2, -3}, {0, 5, 0}, {-1, 64, -1}, {-64, 64, -1}, {-65, 64, -2},
+	}
+	for _, tt := range tests {
+		if q := FloorDiv(tt.a, tt.b); q != tt.want {
+			t.Errorf("FloorDiv(%d, %d) = %d, want %d", tt.a, tt.b, q, tt.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	// The torus runs from -w/2 to w-w/2-1
+	torus := Torus{Width: 5, Height: 4}
+	tests := []struct{ in, want Coord }{
+		{Coord{X: 0, Y: 0}, Coord{X: 0, Y: 0}},
+		{Coord{X: 2, Y: 1}, Coord{X: 2, Y: 1}},
+		{Coord{X: 3, Y: 2}, Coord{X: -2, Y: -2}},
+		{Coord{X: -3, Y: -3}, Coord{X: 2, Y: 1}},
+		{Coord{X: 12, Y: -9}, Coord{X: 2, Y: -1}},
+	}
+	for _, tt := range tests {
+		if c := torus.Wrap(tt.in); c != tt.want {
+			t.Errorf("%v wraps to %v on a %s, want %v", tt.in, c, torus, tt.want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package engine implements Conway's Game Of Life
+// ----------------------------------------------
+//
+// Using a map for storing the current state of the world.
+//
+// This is just an exercise for using maps in go! Do not take this
+// too serious...
+//
+// The World methods compute generations of Conway's Game of Life on the
+// unbounded plane, an Engine does so for other rules and topologies and
+// HashLife skips ahead through long runs. Patterns and the file formats
+// they come in are in package pattern.
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// We are storing the cells (alive or dead) in a map. The keys are the Cartesian
+// coordinates of the cells and the values are the properties of the cells,
+// namely their state, number of alive neighbours and age.
+
+// A cell has its state, its number of life neighbours, and its age: the
+// number of generations a live cell has survived, 0 in the generation it
+// was born in. Under a Generations rule a cell that dies decays before it
+// is dead; State counts its decay states from 1, and is 0 for live and
+// dead cells.
+type Cell struct {
+	Alive bool
+	N     int
+	Age   int
+	State uint8
+}
+
+// The coordinates are plain 2-d cartesian coordinates
+type Coord struct {
+	X int
+	Y int
+}
+
+// The world is a map of Coord and Cell
+type World map[Coord]Cell
+
+// Inflate inflates the world with dead cells surrounding
+// the live cells
+func (world World) Inflate() World {
+	return world.inflate(nil)
+}
+
+// inflate is Inflate with the coordinates of the new cells passed through
+// wrap, if it is not nil
+func (world World) inflate(wrap func(Coord) Coord) World {
+	var newWorld World
+	newWorld = make(World)
+
+	for coord, cell := range world {
+		newWorld[coord] = cell
+		if !cell.Alive {
+			continue
+		}
+		for i := -1; i < 2; i++ {
+			for j := -1; j < 2; j++ {
+				c := Coord{coord.X + i, coord.Y + j}
+				if wrap != nil {
+					c = wrap(c)
+				}
+				if _, found := newWorld[c]; !found {
+					newWorld[c] = Cell{false, 0, 0, 0}
+				}
+			}
+		}
+	}
+
+	return newWorld
+}
+
+// Deflate deflates the world: only the live and decaying cells remain
+func (world World) Deflate() World {
+	var newWorld World
+	newWorld = make(World)
+
+	for coord, cell := range world {
+		if cell.Alive || cell.State > 0 {
+			newWorld[coord] = cell
+		}
+	}
+
+	return newWorld
+}
+
+// CountLiveNeighbours counts for each cell in the world its neighbouring
+// alive cells and updates its counter
+func (world World) CountLiveNeighbours() World {
+	return world.countLiveNeighbours(nil)
+}
+
+// countLiveNeighbours is CountLiveNeighbours with the coordinates of the
+// neighbours passed through wrap, if it is not nil
+func (world World) countLiveNeighbours(wrap func(Coord) Coord) World {
+	var newWorld World
+	newWorld = make(World)
+
+	for coord, cell := range world {
+		n := 0
+		for i := -1; i < 2; i++ {
+			for j := -1; j < 2; j++ {
+				c := Coord{coord.X + i, coord.Y + j}
+				if wrap != nil {
+					c = wrap(c)
+				}
+				if (i != 0 || j != 0) && world[c].Alive {
+					n = n + 1
+				}
+			}
+		}
+		newWorld[coord] = Cell{cell.Alive, n, cell.Age, cell.State}
+	}
+
+	return newWorld
+}
+
+// ApplyRules applies the rules to each cell of the world. This determines
+// the fate of the cell for the next tick. Cells that die or stay dead are
+// kept as dead cells; it is up to Deflate or a PrunePolicy to drop them.
+func (world World) ApplyRules() World {
+	return world.ApplyRule(Conway)
+}
+
+// ApplyRule is ApplyRules for any life-like or Generations rule
+func (world World) ApplyRule(rule Rule) World {
+	var newWorld World
+	newWorld = make(World)
+
+	// apply the rules of the game to each cell
+	for coord, cell := range world {
+		newWorld[coord] = rule.next(cell)
+	}
+
+	return newWorld
+}
+
+// Evolve computes the next generation of the world, keeping the dead
+// cells around the live ones
+func (world World) Evolve() World {
+	return world.Inflate().CountLiveNeighbours().ApplyRules()
+}
+
+// Tick computes the next generation of live cells in the world
+func (world World) Tick() World {
+	return world.Evolve().Deflate()
+}
+
+// LiveCells returns the coordinates of the live cells of the world,
+// ordered by y and then by x, so that output built from them does not
+// depend on the iteration order of the map
+func (world World) LiveCells() []Coord {
+	coords := make([]Coord, 0, len(world))
+	for coord, cell := range world {
+		if cell.Alive {
+			coords = append(coords, coord)
+		}
+	}
+	SortCoords(coords)
+	return coords
+}
+
+// MarshalJSON writes the live cells of the world as a list of [x, y]
+// pairs, in the order of LiveCells
+func (world World) MarshalJSON() ([]byte, error) {
+	cells := world.LiveCells()
+	pairs := make([][2]int, len(cells))
+	for i, c := range cells {
+		pairs[i] = [2]int{c.X, c.Y}
+	}
+	return json.Marshal(pairs)
+}
+
+// UnmarshalJSON brings the cells of a list of [x, y] pairs to life in the
+// world, as written by MarshalJSON. The cells are added to the cells the
+// world has already.
+func (world *World) UnmarshalJSON(data []byte) error {
+	var pairs [][]int
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return err
+	}
+	if *world == nil {
+		*world = make(World, len(pairs))
+	}
+	for i, p := range pairs {
+		if len(p) != 2 {
+			return fmt.Errorf("cell %d: expected [x, y], got %d numbers", i, len(p))
+		}
+		(*world)[Coord{p[0], p[1]}] = Cell{true, 0, 0, 0}
+	}
+	return nil
+}
+
+// SortCoords orders coordinates by y and then by x
+func SortCoords(coords []Coord) {
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].Y != coords[j].Y {
+			return coords[i].Y < coords[j].Y
+		}
+		return coords[i].X < coords[j].X
+	})
+}
+
+// Bounds returns the lower left and upper right corner of the smallest
+// rectangle containing all the given coordinates
+func Bounds(coords []Coord) (min, max Coord) {
+	for i, c := range coords {
+		if i == 0 {
+			min, max = c, c
+			continue
+		}
+		if c.X < min.X {
+			min.X = c.X
+		}
+		if c.Y < min.Y {
+			min.Y = c.Y
+		}
+		if c.X > max.X {
+			max.X = c.X
+		}
+		if c.Y > max.Y {
+			max.Y = c.Y
+		}
+	}
+	return min, max
+}
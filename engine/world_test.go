@@ -0,0 +1,40 @@
+package engine
+
+import "testing"
+
+func TestCellAge(t *testing.T) {
+	// The cells of a still life age with every generation, the cells of a
+	// blinker die or are born anew but for the middle one
+	world := NewWorldFromCells(Coords(
+		Coord{X: 0, Y: 0}, Coord{X: 1, Y: 0}, Coord{X: 0, Y: 1}, Coord{X: 1, Y: 1},
+		Coord{X: 10, Y: 0}, Coord{X: 11, Y: 0}, Coord{X: 12, Y: 0},
+	))
+	for i := 0; i < 5; i++ {
+		world = world.Tick()
+	}
+	if age := world[Coord{}].Age; age != 5 {
+		t.Errorf("block cell %d generations old after 5 generations, want 5", age)
+	}
+	if age := world[Coord{X: 11}].Age; age != 5 {
+		t.Errorf("middle of the blinker %d generations old after 5 generations, want 5", age)
+	}
+	if age := world[Coord{X: 11, Y: 1}].Age; age != 0 {
+		t.Errorf("end of the blinker %d generations old, want 0", age)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	world := make(World)
+	FillRandomSoup(world, 1, 64, 1)
+	b, err := world.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read World
+	if err := read.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if read.Hash() != world.Hash() {
+		t.Errorf("%d cells read back as %d cells", len(world), len(read))
+	}
+}
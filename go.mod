@@ -0,0 +1,3 @@
+module github.com/miromotl/gol
+
+go 1.21
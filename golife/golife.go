@@ -0,0 +1,142 @@
+// Package golife is the old home of the engine and the pattern formats.
+//
+// Deprecated: Use package engine for worlds, rules and engines and package
+// pattern for patterns and their file formats. The names below forward to
+// them and will be removed in v2.
+package golife
+
+import (
+	"image"
+	"io"
+
+	"github.com/miromotl/gol/engine"
+	"github.com/miromotl/gol/pattern"
+)
+
+// Deprecated: Use engine.Cell.
+type Cell = engine.Cell
+
+// Deprecated: Use engine.CellSeq.
+type CellSeq = engine.CellSeq
+
+// Deprecated: Use engine.Coord.
+type Coord = engine.Coord
+
+// Deprecated: Use engine.Engine.
+type Engine = engine.Engine
+
+// Deprecated: Use engine.HashLife.
+type HashLife = engine.HashLife
+
+// Deprecated: Use engine.PhaseTimings.
+type PhaseTimings = engine.PhaseTimings
+
+// Deprecated: Use engine.PrunePolicy.
+type PrunePolicy = engine.PrunePolicy
+
+// Deprecated: Use engine.Region.
+type Region = engine.Region
+
+// Deprecated: Use engine.Rule.
+type Rule = engine.Rule
+
+// Deprecated: Use engine.Torus.
+type Torus = engine.Torus
+
+// Deprecated: Use engine.World.
+type World = engine.World
+
+// Deprecated: Use pattern.Pattern.
+type Pattern = pattern.Pattern
+
+// Deprecated: Use engine.Conway.
+var Conway = engine.Conway
+
+// Deprecated: Use engine.PruneAlways.
+var PruneAlways = engine.PruneAlways
+
+// Deprecated: Use engine.CheckProperties.
+func CheckProperties(e Engine, seed int64, trials int) []string {
+	return engine.CheckProperties(e, seed, trials)
+}
+
+// Deprecated: Use engine.FillAshField.
+func FillAshField(world World, seed int64, size int, density float64) {
+	engine.FillAshField(world, seed, size, density)
+}
+
+// Deprecated: Use engine.FillRandomSoup.
+func FillRandomSoup(world World, seed int64, size int, workers int) {
+	engine.FillRandomSoup(world, seed, size, workers)
+}
+
+// Deprecated: Use engine.Hamming.
+func Hamming(a, b World) int { return engine.Hamming(a, b) }
+
+// Deprecated: Use engine.SortCoords.
+func SortCoords(coords []Coord) { engine.SortCoords(coords) }
+
+// Deprecated: Use engine.VerifyRules.
+func VerifyRules(rule Rule) []string { return engine.VerifyRules(rule) }
+
+// Deprecated: Use engine.Coords.
+func Coords(coords ...Coord) CellSeq { return engine.Coords(coords...) }
+
+// Deprecated: Use engine.Bounds.
+func Bounds(coords []Coord) (min, max Coord) { return engine.Bounds(coords) }
+
+// Deprecated: Use engine.DetectPeriod.
+func DetectPeriod(world World, maxGen int) (period int, shift Coord, ok bool) {
+	return engine.DetectPeriod(world, maxGen)
+}
+
+// Deprecated: Use engine.NewHashLife.
+func NewHashLife(rule Rule, world World) *HashLife { return engine.NewHashLife(rule, world) }
+
+// Deprecated: Use engine.ParsePrunePolicy.
+func ParsePrunePolicy(s string) (PrunePolicy, error) { return engine.ParsePrunePolicy(s) }
+
+// Deprecated: Use engine.ParseRegion.
+func ParseRegion(s string) (Region, error) { return engine.ParseRegion(s) }
+
+// Deprecated: Use engine.ParseRule.
+func ParseRule(s string) (Rule, error) { return engine.ParseRule(s) }
+
+// Deprecated: Use engine.NewWorldFromCells.
+func NewWorldFromCells(cells CellSeq) World { return engine.NewWorldFromCells(cells) }
+
+// Deprecated: Use pattern.FormatCoordinates.
+func FormatCoordinates(p Pattern) string { return pattern.FormatCoordinates(p) }
+
+// Deprecated: Use pattern.WriteCells.
+func WriteCells(w io.Writer, p Pattern) error { return pattern.WriteCells(w, p) }
+
+// Deprecated: Use pattern.Load.
+func LoadPattern(path string) (Pattern, error) { return pattern.Load(path) }
+
+// Deprecated: Use pattern.FromWorld.
+func PatternFromWorld(world World) Pattern { return pattern.FromWorld(world) }
+
+// Deprecated: Use pattern.ParseCoordinates.
+func ParseCoordinates(s string) (Pattern, error) { return pattern.ParseCoordinates(s) }
+
+// Deprecated: Use pattern.ReadCells.
+func ReadCells(r io.Reader) (Pattern, error) { return pattern.ReadCells(r) }
+
+// Deprecated: Use pattern.ReadLife.
+func ReadLife(r io.Reader) (Pattern, error) { return pattern.ReadLife(r) }
+
+// Deprecated: Use pattern.ReadRLE.
+func ReadRLE(r io.Reader) (Pattern, error) { return pattern.ReadRLE(r) }
+
+// Deprecated: Use pattern.ParseRLE.
+func ParseRLE(r io.Reader) (World, error) { return pattern.ParseRLE(r) }
+
+// Deprecated: Use pattern.FromRLEString.
+func FromRLEString(s string) (World, error) { return pattern.FromRLEString(s) }
+
+// Deprecated: Use pattern.FromText.
+func FromText(s string) (World, error) { return pattern.FromText(s) }
+
+// Deprecated: Use pattern.FromImage.
+func FromImage(img image.Image) World { return pattern.FromImage(img) }
@@ -0,0 +1,63 @@
+package pattern
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// FromRLEString returns the world drawn by a pattern in RLE format
+func FromRLEString(s string) (engine.World, error) {
+	return ParseRLE(strings.NewReader(s))
+}
+
+// FromText returns the world drawn as text, one line per row and one
+// character per cell. O, o, *, # and X mark live cells, ., -, _ and spaces
+// dead ones, so both .cells files and quick sketches can be read. Lines
+// starting with ! are comments. The first character of the first row is
+// at the origin and rows run downwards, so row r is at y = -r.
+func FromText(s string) (engine.World, error) {
+	world := make(engine.World)
+	y := 0
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "!") {
+			continue
+		}
+		for x, ch := range []rune(line) {
+			switch ch {
+			case 'O', 'o', '*', '#', 'X':
+				world[engine.Coord{X: x, Y: -y}] = engine.Cell{Alive: true}
+			case '.', '-', '_', ' ', '\t':
+			default:
+				return nil, fmt.Errorf("text line %d: unexpected %q", lineNo, ch)
+			}
+		}
+		y++
+	}
+	return world, scanner.Err()
+}
+
+// FromImage returns the world drawn in an image, one pixel per cell. Dark
+// opaque pixels are live cells, light or transparent ones dead cells. The
+// top left pixel of the image is at the origin and rows run downwards, so
+// pixel row r is at y = -r.
+func FromImage(img image.Image) engine.World {
+	world := make(engine.World)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			g := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+			_, _, _, a := img.At(x, y).RGBA()
+			if a >= 0x8000 && g.Y < 0x8000 {
+				world[engine.Coord{X: x - b.Min.X, Y: b.Min.Y - y}] = engine.Cell{Alive: true}
+			}
+		}
+	}
+	return world
+}
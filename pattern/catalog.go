@@ -0,0 +1,57 @@
+package pattern
+
+import (
+	"fmt"
+	"strings"
+)
+
+// catalog holds well-known patterns in RLE, without the header line. The
+// still lifes and oscillators come first, then the spaceships, the guns
+// and the methuselahs.
+var catalog = []struct {
+	name, comment, rle string
+}{
+	{"block", "the most common still life", "2o$2o!"},
+	{"beehive", "the second most common still life", "b2o$o2bo$b2o!"},
+	{"loaf", "a still life", "b2o$o2bo$bobo$2bo!"},
+	{"boat", "a still life", "2o$obo$bo!"},
+	{"ship", "a still life", "2o$obo$b2o!"},
+	{"tub", "a still life", "bo$obo$bo!"},
+	{"pond", "a still life", "b2o$o2bo$o2bo$b2o!"},
+	{"blinker", "the smallest oscillator, period 2", "3o!"},
+	{"toad", "an oscillator of period 2", "b3o$3o!"},
+	{"beacon", "an oscillator of period 2", "2o$2o$2b2o$2b2o!"},
+	{"pulsar", "an oscillator of period 3", "2b3o3b3o2$o4bobo4bo$o4bobo4bo$o4bobo4bo$2b3o3b3o2$2b3o3b3o$o4bobo4bo$o4bobo4bo$o4bobo4bo2$2b3o3b3o!"},
+	{"pentadecathlon", "an oscillator of period 15", "2bo4bo$2ob4ob2o$2bo4bo!"},
+	{"glider", "the smallest spaceship, moving diagonally at c/4", "bo$2bo$3o!"},
+	{"lwss", "the lightweight spaceship, moving orthogonally at c/2", "bo2bo$o$o3bo$4o!"},
+	{"mwss", "the middleweight spaceship, moving orthogonally at c/2", "3bo$bo3bo$o$o4bo$5o!"},
+	{"hwss", "the heavyweight spaceship, moving orthogonally at c/2", "3b2o$bo4bo$o$o5bo$6o!"},
+	{"gosper-gun", "Gosper's glider gun, firing a glider every 30 generations", "24bo$22bobo$12b2o6b2o12b2o$11bo3bo4b2o12b2o$2o8bo5bo3b2o$2o8bo3bob2o4bobo$10bo5bo7bo$11bo3bo$12b2o!"},
+	{"r-pentomino", "a methuselah stabilizing after 1103 generations", "b2o$2o$bo!"},
+	{"acorn", "a methuselah stabilizing after 5206 generations", "bo$3bo$2o2b3o!"},
+	{"diehard", "a methuselah dying out after 130 generations", "6bo$2o$bo3b3o!"},
+}
+
+// Names returns the names of the patterns in the catalog, in the order of
+// the catalog
+func Names() []string {
+	names := make([]string, len(catalog))
+	for i, c := range catalog {
+		names[i] = c.name
+	}
+	return names
+}
+
+// Named returns the pattern with the given name from the catalog of
+// well-known patterns
+func Named(name string) (Pattern, error) {
+	for _, c := range catalog {
+		if c.name == name {
+			p, err := ReadRLE(strings.NewReader("x = 0, y = 0\n" + c.rle))
+			p.Name, p.Comment = c.name, c.comment
+			return p, err
+		}
+	}
+	return Pattern{}, fmt.Errorf("unknown pattern %q", name)
+}
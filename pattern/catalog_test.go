@@ -0,0 +1,31 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+func TestCatalog(t *testing.T) {
+	// The oscillators and spaceships of the catalog have to repeat with
+	// their well-known periods
+	periods := map[string]int{"block": 1, "blinker": 2, "pulsar": 3, "pentadecathlon": 15, "glider": 4, "lwss": 4, "mwss": 4, "hwss": 4}
+	for _, name := range Names() {
+		p, err := Named(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(p.Cells) == 0 {
+			t.Errorf("%s has no live cells", name)
+		}
+		if want, found := periods[name]; found {
+			if period, _, _ := engine.DetectPeriod(p.World(), 30); period != want {
+				t.Errorf("%s has period %d, want %d", name, period, want)
+			}
+		}
+	}
+	if _, err := Named("no-such-pattern"); err == nil {
+		t.Errorf("an unknown pattern is found in the catalog")
+	}
+}
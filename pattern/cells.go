@@ -0,0 +1,91 @@
+package pattern
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// ReadCells reads a pattern in the plaintext .cells format of the LifeWiki:
+// one line per row with O for live and . for dead cells. Lines starting
+// with ! are comments, a !Name: line names the pattern. As with RLE, rows
+// run downwards, so the cell in row r, column c is at x = c, y = -r.
+func ReadCells(r io.Reader) (Pattern, error) {
+	var p Pattern
+	var comments []string
+	y := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if strings.HasPrefix(line, "!") {
+			text := strings.TrimSpace(line[1:])
+			if name, found := strings.CutPrefix(text, "Name:"); found {
+				p.Name = strings.TrimSpace(name)
+			} else {
+				comments = append(comments, text)
+			}
+			continue
+		}
+
+		// Empty lines are rows without live cells
+		for x, ch := range line {
+			switch ch {
+			case 'O', '*':
+				p.Cells = append(p.Cells, engine.Coord{X: x, Y: y})
+			case '.':
+			default:
+				return p, fmt.Errorf("cells line %d: unexpected %q", lineNo, ch)
+			}
+		}
+		y++
+	}
+
+	p.Comment = strings.Join(comments, "\n")
+	return p.upsideDown(), scanner.Err()
+}
+
+// WriteCells writes the pattern in plaintext .cells format, with the top
+// left corner of its bounding box in the first column of the first row.
+// The format has no decay states, so a pattern with decaying cells is an
+// error.
+func WriteCells(w io.Writer, p Pattern) error {
+	if len(p.Decaying) > 0 {
+		return fmt.Errorf("cells: cannot write the %d decaying cells of a Generations pattern, the format has only live and dead cells", len(p.Decaying))
+	}
+	bw := bufio.NewWriter(w)
+	if p.Name != "" {
+		fmt.Fprintf(bw, "!Name: %s\n", p.Name)
+	}
+	if p.Comment != "" {
+		for _, line := range strings.Split(p.Comment, "\n") {
+			fmt.Fprintf(bw, "!%s\n", line)
+		}
+	}
+
+	if len(p.Cells) > 0 {
+		q := p.upsideDown().Normalize()
+		_, max := q.Bounds()
+		row := make([]byte, max.X+1)
+		i := 0
+		for y := 0; y <= max.Y; y++ {
+			for x := range row {
+				row[x] = '.'
+			}
+			end := 0
+			// The cells are sorted by y, then x
+			for ; i < len(q.Cells) && q.Cells[i].Y == y; i++ {
+				row[q.Cells[i].X] = 'O'
+				end = q.Cells[i].X + 1
+			}
+			bw.Write(row[:end])
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,64 @@
+package pattern
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+func TestCellsRoundTrip(t *testing.T) {
+	for _, name := range Names() {
+		p, _ := Named(name)
+		p.Comment = "first line\nsecond line"
+		var b bytes.Buffer
+		if err := WriteCells(&b, p); err != nil {
+			t.Fatal(err)
+		}
+		q, err := ReadCells(&b)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if q.Name != p.Name || q.Comment != p.Comment {
+			t.Errorf("%s reads back as %q, %q", name, q.Name, q.Comment)
+		}
+		if !sameCells(q.Normalize().Cells, p.Normalize().Cells) {
+			t.Errorf("%s of %d cells reads back as %d cells", name, len(p.Cells), len(q.Cells))
+		}
+	}
+}
+
+func TestReadCells(t *testing.T) {
+	p, err := ReadCells(strings.NewReader("!Name: glider\n!moves\n.O\n..O\r\nOOO   \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "glider" || p.Comment != "moves" {
+		t.Errorf("glider reads as %q, %q", p.Name, p.Comment)
+	}
+	want := []engine.Coord{{X: 1, Y: 0}, {X: 2, Y: -1}, {X: 0, Y: -2}, {X: 1, Y: -2}, {X: 2, Y: -2}}
+	if !sameCells(p.Cells, want) {
+		t.Errorf("glider reads as %v, want %v", p.Cells, want)
+	}
+
+	// Empty lines are rows without live cells
+	if p, _ := ReadCells(strings.NewReader("O\n\n*\n")); !sameCells(p.Cells, []engine.Coord{{X: 0, Y: 0}, {X: 0, Y: -2}}) {
+		t.Errorf("cells with an empty row read as %v", p.Cells)
+	}
+
+	for _, in := range []string{"O.x\n", ".O\nbo\n"} {
+		if p, err := ReadCells(strings.NewReader(in)); err == nil {
+			t.Errorf("%q reads as %v, want an error", in, p.Cells)
+		}
+	}
+}
+
+func TestWriteCellsDecaying(t *testing.T) {
+	// The format cannot hold decay states, so they must not get lost
+	p := Pattern{Rule: "/2/3", Cells: []engine.Coord{{X: 0, Y: 0}}, Decaying: map[engine.Coord]uint8{{X: 1, Y: 0}: 1}}
+	if err := WriteCells(&bytes.Buffer{}, p); err == nil {
+		t.Errorf("a pattern with decaying cells is written without an error")
+	}
+}
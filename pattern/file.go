@@ -0,0 +1,44 @@
+package pattern
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extensions are the file name extensions of the formats Load reads
+var Extensions = []string{".rle", ".cells", ".lif", ".life", ".mc"}
+
+// Load reads a pattern from a file, choosing the format by the
+// extension of the file name. Patterns without a name are named after
+// the file.
+func Load(path string) (Pattern, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Pattern{}, err
+	}
+	defer f.Close()
+
+	var p Pattern
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".rle":
+		p, err = ReadRLE(f)
+	case ".cells":
+		p, err = ReadCells(f)
+	case ".lif", ".life":
+		p, err = ReadLife(f)
+	case ".mc":
+		p, err = ReadMacrocell(f)
+	default:
+		return p, fmt.Errorf("%s: unknown pattern format %q", path, ext)
+	}
+	if err != nil {
+		return p, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if p.Name == "" {
+		p.Name = filepath.Base(path)
+	}
+	return p, nil
+}
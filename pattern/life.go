@@ -0,0 +1,121 @@
+package pattern
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// ReadLife reads a pattern in Life 1.05 or Life 1.06 format, telling them
+// apart by the #Life header line.
+//
+// Life 1.06 is a plain list of x y coordinates, one cell per line. Life
+// 1.05 describes the pattern in blocks: a #P x y line gives the position
+// of the upper left corner of the block, and the following lines draw the
+// block with * for live and . for dead cells. #D lines are comments and
+// #R s/b gives the rule. In both, y runs downwards as the rows do, so a
+// cell at x y in the file is at x, -y in the pattern.
+func ReadLife(r io.Reader) (Pattern, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return Pattern{}, err
+		}
+		return Pattern{}, fmt.Errorf("life: empty file")
+	}
+
+	switch header := strings.TrimSpace(scanner.Text()); header {
+	case "#Life 1.06":
+		return readLife106(scanner)
+	case "#Life 1.05":
+		return readLife105(scanner)
+	default:
+		return Pattern{}, fmt.Errorf("life: unknown header %q", header)
+	}
+}
+
+func readLife106(scanner *bufio.Scanner) (Pattern, error) {
+	var p Pattern
+	var comments []string
+
+	for lineNo := 2; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if tag, text := rleComment(line); tag == "D" || tag == "C" {
+				comments = append(comments, text)
+			}
+			continue
+		}
+
+		var c engine.Coord
+		if _, err := fmt.Sscanf(line, "%d %d", &c.X, &c.Y); err != nil {
+			return p, fmt.Errorf("life line %d: invalid coordinate %q", lineNo, line)
+		}
+		p.Cells = append(p.Cells, c)
+	}
+
+	p.Comment = strings.Join(comments, "\n")
+	return p.upsideDown(), scanner.Err()
+}
+
+func readLife105(scanner *bufio.Scanner) (Pattern, error) {
+	var p Pattern
+	var comments []string
+	var block engine.Coord
+	row := 0
+	inBlock := false
+
+	for lineNo := 2; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			tag, text := rleComment(line)
+			switch tag {
+			case "D", "C":
+				comments = append(comments, text)
+			case "N":
+				p.Rule = engine.Conway.String()
+			case "R":
+				survival, birth, found := strings.Cut(text, "/")
+				if !found {
+					return p, fmt.Errorf("life line %d: invalid rule %q", lineNo, text)
+				}
+				p.Rule = "B" + birth + "/S" + survival
+			case "P":
+				if _, err := fmt.Sscanf(text, "%d %d", &block.X, &block.Y); err != nil {
+					return p, fmt.Errorf("life line %d: invalid block position %q", lineNo, text)
+				}
+				row = 0
+				inBlock = true
+			}
+			continue
+		}
+
+		if !inBlock {
+			return p, fmt.Errorf("life line %d: cells before the first #P line", lineNo)
+		}
+		for x, ch := range line {
+			switch ch {
+			case '*':
+				p.Cells = append(p.Cells, engine.Coord{X: block.X + x, Y: block.Y + row})
+			case '.':
+			default:
+				return p, fmt.Errorf("life line %d: unexpected %q", lineNo, ch)
+			}
+		}
+		row++
+	}
+
+	p.Comment = strings.Join(comments, "\n")
+	return p.upsideDown(), scanner.Err()
+}
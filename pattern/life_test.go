@@ -0,0 +1,53 @@
+package pattern
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+func TestReadLife(t *testing.T) {
+	// The same glider in both versions, 1.05 with two blocks
+	life106 := "#Life 1.06\n#D a glider\n1 0\n2 1\n0 2\n1 2\n2 2\n"
+	life105 := "#Life 1.05\n#D a glider\n#R 23/3\n#P 1 0\n*\n.*\n#P 0 2\n***\n"
+	// y runs downwards in the files
+	want := []engine.Coord{{X: 1, Y: 0}, {X: 2, Y: -1}, {X: 0, Y: -2}, {X: 1, Y: -2}, {X: 2, Y: -2}}
+
+	for _, in := range []string{life106, life105} {
+		p, err := ReadLife(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Comment != "a glider" {
+			t.Errorf("comment %q, want a glider", p.Comment)
+		}
+		if !sameCells(p.Cells, want) {
+			t.Errorf("glider reads as %v, want %v", p.Cells, want)
+		}
+	}
+	if p, _ := ReadLife(strings.NewReader(life105)); p.Rule != "B3/S23" {
+		t.Errorf("rule %q, want B3/S23", p.Rule)
+	}
+	if p, _ := ReadLife(strings.NewReader("#Life 1.05\n#N\n")); p.Rule != "B3/S23" {
+		t.Errorf("#N gives the rule %q, want B3/S23", p.Rule)
+	}
+}
+
+func TestReadLifeErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"#Life 2.0\n",
+		"1 2\n",
+		"#Life 1.06\n1\n",
+		"#Life 1.06\nx y\n",
+		"#Life 1.05\n*\n",
+		"#Life 1.05\n#P 0\n*\n",
+		"#Life 1.05\n#R 23\n",
+		"#Life 1.05\n#P 0 0\n*o\n",
+	} {
+		if p, err := ReadLife(strings.NewReader(in)); err == nil {
+			t.Errorf("%q reads as %v, want an error", in, p.Cells)
+		}
+	}
+}
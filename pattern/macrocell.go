@@ -0,0 +1,256 @@
+package pattern
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// The macrocell format of Golly stores a pattern as a quadtree in which
+// identical subtrees are written only once, so huge patterns made of
+// repeated parts, like metapixel constructions, stay small. Every line
+// after the header is a node, numbered from 1. A leaf is an 8x8 block of
+// cells written like a tiny RLE without counts: . for dead, * for live
+// cells and $ at the end of a row, leaving out dead cells at the end of a
+// row and rows at the end of the block. A node of level k > 3, covering
+// 2^k x 2^k cells, is "k nw ne sw se" with the numbers of its quadrants, 0
+// for an empty one. The last node is the root, and its center is at the
+// origin. Rows run downwards, as in RLE, so y is negated on the way in
+// and out.
+
+// macrocellLeaf is the level of the 8x8 leaves
+const macrocellLeaf = 3
+
+// a macrocellNode is a node of a macrocell file, with the live cells of a
+// leaf as a bitmap of 8 rows of 8 bits, bit x of a row is column x
+type macrocellNode struct {
+	level    int
+	children [4]int // nw, ne, sw, se
+	rows     [8]uint8
+}
+
+// ReadMacrocell reads a two-state pattern in Golly's macrocell format. A
+// #R line sets the rule, a #N line the name, #C and #D lines are comments.
+func ReadMacrocell(r io.Reader) (Pattern, error) {
+	var p Pattern
+	var comments []string
+	nodes := []macrocellNode{{}} // node 0 is the empty node
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case lineNo == 1:
+			if !strings.HasPrefix(line, "[M2]") {
+				return p, fmt.Errorf("macrocell line 1: expected a [M2] header, got %q", line)
+			}
+			continue
+		case strings.HasPrefix(line, "#"):
+			tag, text := rleComment(line)
+			switch tag {
+			case "N":
+				p.Name = text
+			case "C", "D":
+				comments = append(comments, text)
+			case "R":
+				p.Rule = text
+			}
+			continue
+		}
+
+		var n macrocellNode
+		if line[0] == '.' || line[0] == '*' || line[0] == '$' {
+			n.level = macrocellLeaf
+			x, y := 0, 0
+			for _, ch := range line {
+				switch {
+				case ch == '$':
+					x, y = 0, y+1
+				case x >= 8 || y >= 8:
+					return p, fmt.Errorf("macrocell line %d: leaf larger than 8x8", lineNo)
+				case ch == '*':
+					n.rows[y] |= 1 << x
+					x++
+				case ch == '.':
+					x++
+				default:
+					return p, fmt.Errorf("macrocell line %d: unexpected %q", lineNo, ch)
+				}
+			}
+		} else {
+			fields := strings.Fields(line)
+			if len(fields) != 5 {
+				return p, fmt.Errorf("macrocell line %d: invalid node %q", lineNo, line)
+			}
+			level, err := strconv.Atoi(fields[0])
+			if err != nil || level <= macrocellLeaf || level > 62 {
+				if err == nil && level == 1 {
+					return p, fmt.Errorf("macrocell line %d: only two-state patterns are supported", lineNo)
+				}
+				return p, fmt.Errorf("macrocell line %d: invalid level %q", lineNo, fields[0])
+			}
+			n.level = level
+			for i, f := range fields[1:] {
+				child, err := strconv.Atoi(f)
+				if err != nil || child < 0 || child >= len(nodes) {
+					return p, fmt.Errorf("macrocell line %d: invalid node number %q", lineNo, f)
+				}
+				if child > 0 && nodes[child].level != level-1 {
+					return p, fmt.Errorf("macrocell line %d: node %d is not of level %d", lineNo, child, level-1)
+				}
+				n.children[i] = child
+			}
+		}
+		nodes = append(nodes, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return p, err
+	}
+
+	if root := len(nodes) - 1; root > 0 {
+		h := 1 << (nodes[root].level - 1)
+		p.Cells = macrocellCells(nodes, root, engine.Coord{X: -h, Y: -h}, p.Cells)
+		p = p.upsideDown()
+		engine.SortCoords(p.Cells)
+	}
+	p.Comment = strings.Join(comments, "\n")
+	return p, nil
+}
+
+// macrocellCells appends the live cells of node i, with its top left
+// corner at c, to cells
+func macrocellCells(nodes []macrocellNode, i int, c engine.Coord, cells []engine.Coord) []engine.Coord {
+	n := nodes[i]
+	if i == 0 {
+		return cells
+	}
+	if n.level == macrocellLeaf {
+		for y, row := range n.rows {
+			for x := 0; x < 8; x++ {
+				if row&(1<<x) != 0 {
+					cells = append(cells, engine.Coord{X: c.X + x, Y: c.Y + y})
+				}
+			}
+		}
+		return cells
+	}
+	h := 1 << (n.level - 1)
+	cells = macrocellCells(nodes, n.children[0], c, cells)
+	cells = macrocellCells(nodes, n.children[1], engine.Coord{X: c.X + h, Y: c.Y}, cells)
+	cells = macrocellCells(nodes, n.children[2], engine.Coord{X: c.X, Y: c.Y + h}, cells)
+	return macrocellCells(nodes, n.children[3], engine.Coord{X: c.X + h, Y: c.Y + h}, cells)
+}
+
+// WriteMacrocell writes the pattern in Golly's macrocell format, with the
+// root centered on the origin of the pattern. Like ReadMacrocell it only
+// knows two states, so a pattern with decaying cells is an error.
+func WriteMacrocell(w io.Writer, p Pattern) error {
+	if len(p.Decaying) > 0 {
+		return fmt.Errorf("macrocell: cannot write the %d decaying cells of a Generations pattern, only two-state patterns are supported", len(p.Decaying))
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "[M2] (gol)")
+	if p.Name != "" {
+		fmt.Fprintf(bw, "#N %s\n", p.Name)
+	}
+	if p.Comment != "" {
+		for _, line := range strings.Split(p.Comment, "\n") {
+			fmt.Fprintf(bw, "#C %s\n", line)
+		}
+	}
+	if p.Rule != "" {
+		fmt.Fprintf(bw, "#R %s\n", p.Rule)
+	}
+
+	if len(p.Cells) > 0 {
+		// The smallest root around the origin that holds all cells, with
+		// the rows running downwards
+		p = p.upsideDown()
+		min, max := p.Bounds()
+		level := macrocellLeaf
+		for h := 1 << (level - 1); min.X < -h || min.Y < -h || max.X >= h || max.Y >= h; h <<= 1 {
+			level++
+		}
+		m := macrocellWriter{w: bw, leaves: make(map[[8]uint8]int), nodes: make(map[[5]int]int)}
+		h := 1 << (level - 1)
+		m.node(level, engine.Coord{X: -h, Y: -h}, p.Cells)
+	}
+	return bw.Flush()
+}
+
+// A macrocellWriter writes the nodes of a quadtree, every distinct node
+// once, after the nodes it is made of
+type macrocellWriter struct {
+	w      *bufio.Writer
+	leaves map[[8]uint8]int
+	nodes  map[[5]int]int
+	count  int
+}
+
+// node writes the node of the given level with its top left corner at c
+// holding the cells, if it was not written before, and returns its number
+func (m *macrocellWriter) node(level int, c engine.Coord, cells []engine.Coord) int {
+	if len(cells) == 0 {
+		return 0
+	}
+
+	if level == macrocellLeaf {
+		var rows [8]uint8
+		for _, cell := range cells {
+			rows[cell.Y-c.Y] |= 1 << (cell.X - c.X)
+		}
+		if i, found := m.leaves[rows]; found {
+			return i
+		}
+		last := 7
+		for rows[last] == 0 {
+			last--
+		}
+		for _, row := range rows[:last+1] {
+			for ; row != 0; row >>= 1 {
+				if row&1 != 0 {
+					m.w.WriteByte('*')
+				} else {
+					m.w.WriteByte('.')
+				}
+			}
+			m.w.WriteByte('$')
+		}
+		m.w.WriteByte('\n')
+		m.count++
+		m.leaves[rows] = m.count
+		return m.count
+	}
+
+	h := 1 << (level - 1)
+	var quadrants [4][]engine.Coord
+	for _, cell := range cells {
+		q := 0
+		if cell.X >= c.X+h {
+			q |= 1
+		}
+		if cell.Y >= c.Y+h {
+			q |= 2
+		}
+		quadrants[q] = append(quadrants[q], cell)
+	}
+	key := [5]int{level}
+	for q := range quadrants {
+		at := engine.Coord{X: c.X + (q&1)*h, Y: c.Y + (q>>1)*h}
+		key[q+1] = m.node(level-1, at, quadrants[q])
+	}
+	if i, found := m.nodes[key]; found {
+		return i
+	}
+	fmt.Fprintf(m.w, "%d %d %d %d %d\n", key[0], key[1], key[2], key[3], key[4])
+	m.count++
+	m.nodes[key] = m.count
+	return m.count
+}
@@ -0,0 +1,81 @@
+package pattern
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+func TestMacrocellRoundTrip(t *testing.T) {
+	for _, name := range Names() {
+		p, _ := Named(name)
+		p = p.Translate(engine.Coord{X: -5, Y: -3})
+		p.Rule = "B3/S23"
+		var b bytes.Buffer
+		if err := WriteMacrocell(&b, p); err != nil {
+			t.Fatal(err)
+		}
+		q, err := ReadMacrocell(&b)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if q.Name != p.Name || q.Comment != p.Comment || q.Rule != p.Rule {
+			t.Errorf("%s reads back as %q, %q, rule %q", name, q.Name, q.Comment, q.Rule)
+		}
+		// Unlike RLE the macrocell keeps the place of the pattern
+		if !sameCells(q.Cells, p.Cells) {
+			t.Errorf("%s of %d cells reads back as %d cells", name, len(p.Cells), len(q.Cells))
+		}
+	}
+}
+
+func TestMacrocellSize(t *testing.T) {
+	// A tiled pattern has to shrink to a few nodes
+	world := make(engine.World)
+	p, _ := Named("pulsar")
+	p.Tile(world, engine.Coord{X: -100, Y: -60}, 16, 16, 50, 30)
+	p = Pattern{Name: "pulsars", Rule: "B3/S23", Cells: world.LiveCells()}
+	var b bytes.Buffer
+	if err := WriteMacrocell(&b, p); err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() > 2000 {
+		t.Errorf("%d tiled pulsars take %d bytes as a macrocell", 50*30, b.Len())
+	}
+	q, err := ReadMacrocell(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(q.Cells, p.Cells) {
+		t.Errorf("macrocell of %d cells reads back as %d cells", len(p.Cells), len(q.Cells))
+	}
+}
+
+func TestReadMacrocellErrors(t *testing.T) {
+	for _, in := range []string{
+		"#N no header\n",
+		"[M2]\n*********$\n",
+		"[M2]\n*.x$\n",
+		"[M2]\n*$\n4 1 0 0\n",
+		"[M2]\n*$\n4 1 0 0 1\n5 0 0 0 1\n",
+		"[M2]\n*$\n4 1 0 2 0\n",
+		"[M2]\n*$\nx 1 0 0 0\n",
+		"[M2]\n1 0 0 0 0\n",
+	} {
+		if p, err := ReadMacrocell(strings.NewReader(in)); err == nil {
+			t.Errorf("%q reads as %v, want an error", in, p.Cells)
+		}
+	}
+}
+
+func TestWriteMacrocellDecaying(t *testing.T) {
+	// The format cannot hold decay states, so they must not get lost
+	p := Pattern{Rule: "/2/3", Cells: []engine.Coord{{X: 0, Y: 0}}, Decaying: map[engine.Coord]uint8{{X: 1, Y: 0}: 1}}
+	if err := WriteMacrocell(&bytes.Buffer{}, p); err == nil {
+		t.Errorf("a pattern with decaying cells is written without an error")
+	}
+}
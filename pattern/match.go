@@ -0,0 +1,131 @@
+package pattern
+
+import (
+	"github.com/miromotl/gol/engine"
+)
+
+// A Match is an occurrence of the query of a Matcher in a world
+type Match struct {
+	At          engine.Coord // lower left corner of the bounding box of the occurrence
+	Orientation string       // how the query is turned, r0 to r270 or f0 to f270
+}
+
+// A Matcher finds the occurrences of a query pattern, in any of its
+// orientations, in the generations of a running world, as Find does. After
+// the first generation only the places around the cells born or died since
+// the generation before are searched for new occurrences, and only the
+// occurrences found before are checked for having gone, so a generation
+// costs in proportion to the activity of the world rather than its size.
+type Matcher struct {
+	orientations []orientation
+	maxes        []engine.Coord // per orientation, the upper right corner of its bounding box
+	reach        int            // how far a cell deciding an occurrence can be from its first cell
+	exact        bool
+
+	live    map[engine.Coord]bool // the live cells of the generation before
+	matches map[occurrence]bool
+}
+
+// An occurrence is a Match with the index of its orientation, which is
+// cheaper to look up than the name
+type occurrence struct {
+	at engine.Coord
+	i  int
+}
+
+// NewMatcher returns a matcher for the query, which has to have live cells.
+// With exact an occurrence has to stand on its own, as in Find.
+func NewMatcher(query Pattern, exact bool) *Matcher {
+	m := &Matcher{orientations: query.orientations(), exact: exact, matches: make(map[occurrence]bool)}
+	for _, o := range m.orientations {
+		_, corner := o.p.Bounds()
+		m.maxes = append(m.maxes, corner)
+		m.reach = max(m.reach, corner.X, corner.Y)
+	}
+	if exact {
+		m.reach++
+	}
+	return m
+}
+
+// Next searches the next generation of the world and returns the
+// occurrences that were not in the generation before, in any order. An
+// object that stays in place, like a still life, is reported once, when it
+// appears; a moving one, like a glider, in every generation.
+func (m *Matcher) Next(world engine.World) []Match {
+	live := make(map[engine.Coord]bool, len(world))
+	for c, cell := range world {
+		if cell.Alive {
+			live[c] = true
+		}
+	}
+
+	var found []Match
+	// try looks for orientation i with its first cell at c
+	try := func(i int, c engine.Coord) {
+		o := m.orientations[i]
+		first := o.p.Cells[0]
+		at := engine.Coord{X: c.X - first.X, Y: c.Y - first.Y}
+		k := occurrence{at, i}
+		if matches(live, o.p, at, m.maxes[i], m.exact) && !m.matches[k] {
+			m.matches[k] = true
+			found = append(found, Match{at, o.name})
+		}
+	}
+
+	if m.live == nil {
+		// The first generation is searched everywhere
+		for i := range m.orientations {
+			for c := range live {
+				try(i, c)
+			}
+		}
+		m.live = live
+		return found
+	}
+
+	for k := range m.matches {
+		if !matches(live, m.orientations[k.i].p, k.at, m.maxes[k.i], m.exact) {
+			delete(m.matches, k)
+		}
+	}
+
+	// A new occurrence has a cell born since the generation before or,
+	// standing on its own, a cell around it that died. Its first cell is a
+	// live cell within reach of that one; every such cell is tried once.
+	near := make(map[engine.Coord]bool)
+	around := func(c engine.Coord) {
+		for x := c.X - m.reach; x <= c.X+m.reach; x++ {
+			for y := c.Y - m.reach; y <= c.Y+m.reach; y++ {
+				if a := (engine.Coord{X: x, Y: y}); live[a] {
+					near[a] = true
+				}
+			}
+		}
+	}
+	for c := range live {
+		if !m.live[c] {
+			around(c)
+		}
+	}
+	if m.exact {
+		for c := range m.live {
+			if !live[c] {
+				around(c)
+			}
+		}
+	}
+	for c := range near {
+		for i := range m.orientations {
+			try(i, c)
+		}
+	}
+	m.live = live
+	return found
+}
+
+// Matches returns the number of occurrences in the generation searched
+// last
+func (m *Matcher) Matches() int {
+	return len(m.matches)
+}
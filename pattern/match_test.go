@@ -0,0 +1,28 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+func TestMatcher(t *testing.T) {
+	// Carried over from one generation to the next, the occurrences have
+	// to be the ones a search of the whole world finds
+	world := make(engine.World)
+	engine.FillRandomSoup(world, 20150101, 64, 1)
+	for _, name := range []string{"block", "blinker", "glider"} {
+		q, _ := Named(name)
+		for _, exact := range []bool{false, true} {
+			m := NewMatcher(q, exact)
+			w := world
+			for gen := 0; gen < 100; gen++ {
+				m.Next(w)
+				if n, want := m.Matches(), len(NewMatcher(q, exact).Next(w)); n != want {
+					t.Fatalf("%d occurrences of %s (exact %t) in generation %d, want %d", n, name, exact, gen, want)
+				}
+				w = w.Tick()
+			}
+		}
+	}
+}
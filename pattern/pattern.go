@@ -0,0 +1,267 @@
+// Package pattern reads and writes the patterns of Conway's Game Of Life
+// in the plaintext, RLE, Life 1.05/1.06 and macrocell formats and builds
+// worlds of package engine from them.
+//
+// Patterns and worlds have y growing upwards, as the renderers of package
+// render draw them. The files draw their rows from the top down, so the
+// readers put row r of a file at y = -r and the writers write the cells
+// with the largest y first: a pattern looks the same in its file and on
+// the screen.
+package pattern
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// A Pattern is a reusable arrangement of live cells, independent of any
+// world it may be placed into. The cells are relative to the pattern's
+// own origin.
+type Pattern struct {
+	Name     string
+	Comment  string
+	Rule     string // rule the pattern is meant for, empty if unknown
+	Cells    []engine.Coord
+	Decaying map[engine.Coord]uint8 // decay states of the decaying cells of a Generations pattern, as in engine.Cell
+}
+
+// FromWorld captures the live cells of the world as a pattern,
+// shifted so that its bounding box starts at the origin
+func FromWorld(world engine.World) Pattern {
+	var p Pattern
+	for coord, cell := range world {
+		if cell.Alive {
+			p.Cells = append(p.Cells, coord)
+		}
+	}
+	return p.Normalize()
+}
+
+// World returns a new world holding just the pattern at its origin
+func (p Pattern) World() engine.World {
+	world := make(engine.World)
+	p.Place(world, engine.Coord{X: 0, Y: 0})
+	return world
+}
+
+// Place brings the cells of the pattern to life in the world, with the
+// pattern's origin at the given coordinate. Its decaying cells decay there,
+// unless they are alive in the world.
+func (p Pattern) Place(world engine.World, at engine.Coord) {
+	for _, c := range p.Cells {
+		world[engine.Coord{X: at.X + c.X, Y: at.Y + c.Y}] = engine.Cell{Alive: true}
+	}
+	for c, state := range p.Decaying {
+		if c = (engine.Coord{X: at.X + c.X, Y: at.Y + c.Y}); !world[c].Alive {
+			world[c] = engine.Cell{State: state}
+		}
+	}
+}
+
+// Erase kills the cells of the world covered by the pattern placed at the
+// given coordinate
+func (p Pattern) Erase(world engine.World, at engine.Coord) {
+	for _, c := range p.Cells {
+		delete(world, engine.Coord{X: at.X + c.X, Y: at.Y + c.Y})
+	}
+}
+
+// Tile places nx x ny copies of the pattern into the world, spaced w
+// cells apart in x and h cells apart in y, with the origin of the first
+// copy at the given coordinate. A pattern tiled with its period, like an
+// agar, fills a torus of nx*w x ny*h cells without seams.
+func (p Pattern) Tile(world engine.World, at engine.Coord, w, h, nx, ny int) {
+	for i := 0; i < nx; i++ {
+		for j := 0; j < ny; j++ {
+			p.Place(world, engine.Coord{X: at.X + i*w, Y: at.Y + j*h})
+		}
+	}
+}
+
+// Bounds returns the lower left and upper right cell of the pattern
+func (p Pattern) Bounds() (min, max engine.Coord) {
+	return engine.Bounds(p.Cells)
+}
+
+// extent returns the lower left and upper right cell of the live and the
+// decaying cells of the pattern
+func (p Pattern) extent() (lo, hi engine.Coord) {
+	lo, hi = p.Bounds()
+	first := len(p.Cells) == 0
+	for c := range p.Decaying {
+		if first {
+			lo, hi, first = c, c, false
+		}
+		lo = engine.Coord{X: min(lo.X, c.X), Y: min(lo.Y, c.Y)}
+		hi = engine.Coord{X: max(hi.X, c.X), Y: max(hi.Y, c.Y)}
+	}
+	return lo, hi
+}
+
+// Translate returns the pattern moved by d
+func (p Pattern) Translate(d engine.Coord) Pattern {
+	return p.transform(func(c engine.Coord) engine.Coord { return engine.Coord{X: c.X + d.X, Y: c.Y + d.Y} })
+}
+
+// Normalize returns the pattern moved so that its bounding box starts at
+// the origin, with the cells in a canonical order
+func (p Pattern) Normalize() Pattern {
+	min, _ := p.Bounds()
+	q := p.Translate(engine.Coord{X: -min.X, Y: -min.Y})
+	engine.SortCoords(q.Cells)
+	return q
+}
+
+// Rotate returns the pattern rotated by 90 degrees counterclockwise
+// around its origin
+func (p Pattern) Rotate() Pattern {
+	return p.transform(func(c engine.Coord) engine.Coord { return engine.Coord{X: -c.Y, Y: c.X} })
+}
+
+// Flip returns the pattern mirrored at the y axis
+func (p Pattern) Flip() Pattern {
+	return p.transform(func(c engine.Coord) engine.Coord { return engine.Coord{X: -c.X, Y: c.Y} })
+}
+
+// upsideDown returns the pattern mirrored at the x axis, turning the rows
+// of a file, which run downwards, into y, which runs upwards, and back
+func (p Pattern) upsideDown() Pattern {
+	return p.transform(func(c engine.Coord) engine.Coord { return engine.Coord{X: c.X, Y: -c.Y} })
+}
+
+// transform returns the pattern with every cell, live or decaying, moved
+// to f of it
+func (p Pattern) transform(f func(engine.Coord) engine.Coord) Pattern {
+	q := p
+	q.Cells = make([]engine.Coord, len(p.Cells))
+	for i, c := range p.Cells {
+		q.Cells[i] = f(c)
+	}
+	if p.Decaying != nil {
+		q.Decaying = make(map[engine.Coord]uint8, len(p.Decaying))
+		for c, state := range p.Decaying {
+			q.Decaying[f(c)] = state
+		}
+	}
+	return q
+}
+
+// Orientations returns the distinct rotations and reflections of the
+// pattern, normalized and without duplicate cells. A pattern without any
+// symmetry has eight, a block just one.
+func (p Pattern) Orientations() []Pattern {
+	var ps []Pattern
+	for _, o := range p.orientations() {
+		ps = append(ps, o.p)
+	}
+	return ps
+}
+
+// An orientation is one of the distinct rotations and reflections of a
+// pattern, with its name: r0 to r270 for the rotations by multiples of
+// 90 degrees counterclockwise, f0 to f270 for the same after a flip at the
+// y axis
+type orientation struct {
+	name string
+	p    Pattern
+}
+
+// orientations returns the orientations of the pattern in the order of
+// their names. Of orientations that look the same the first is kept.
+func (p Pattern) orientations() []orientation {
+	var list []orientation
+	q := p
+	for i := 0; i < 8; i++ {
+		name := "r"
+		if i >= 4 {
+			name = "f"
+		}
+		if i == 4 {
+			q = p.Flip()
+		}
+		n := q.Normalize()
+		n.Cells = slices.Compact(n.Cells)
+		if !slices.ContainsFunc(list, func(o orientation) bool { return slices.Equal(o.p.Cells, n.Cells) }) {
+			list = append(list, orientation{name + strconv.Itoa(i%4*90), n})
+		}
+		q = q.Rotate()
+	}
+	return list
+}
+
+// Canonical returns the pattern in a form that is the same for all
+// patterns equal up to the symmetries of the grid and translation: the
+// orientation whose cells come first
+func (p Pattern) Canonical() Pattern {
+	orientations := p.Orientations()
+	best := orientations[0]
+	for _, o := range orientations[1:] {
+		if slices.CompareFunc(o.Cells, best.Cells, compareCoords) < 0 {
+			best = o
+		}
+	}
+	return best
+}
+
+// compareCoords orders coordinates like engine.SortCoords
+func compareCoords(a, b engine.Coord) int {
+	if a.Y != b.Y {
+		return cmp.Compare(a.Y, b.Y)
+	}
+	return cmp.Compare(a.X, b.X)
+}
+
+// Hash returns a hash of the canonical cells of the pattern, so that
+// patterns equal up to symmetry and translation hash the same
+func (p Pattern) Hash() uint64 {
+	h := fnv.New64a()
+	var b [16]byte
+	for _, c := range p.Canonical().Cells {
+		binary.LittleEndian.PutUint64(b[:8], uint64(int64(c.X)))
+		binary.LittleEndian.PutUint64(b[8:], uint64(int64(c.Y)))
+		h.Write(b[:])
+	}
+	return h.Sum64()
+}
+
+// ParseCoordinates parses a semicolon-separated list of x,y coordinates,
+// as given to -coordinates, into a pattern
+func ParseCoordinates(s string) (Pattern, error) {
+	p := Pattern{Name: "coordinates"}
+	for _, xy := range strings.Split(s, ";") {
+		x, y, found := strings.Cut(xy, ",")
+		if !found {
+			return p, fmt.Errorf("invalid coordinate %q, expected x,y", xy)
+		}
+		cx, err := strconv.Atoi(strings.TrimSpace(x))
+		if err != nil {
+			return p, err
+		}
+		cy, err := strconv.Atoi(strings.TrimSpace(y))
+		if err != nil {
+			return p, err
+		}
+		p.Cells = append(p.Cells, engine.Coord{X: cx, Y: cy})
+	}
+	return p, nil
+}
+
+// FormatCoordinates writes the cells of the pattern in the format read by
+// ParseCoordinates
+func FormatCoordinates(p Pattern) string {
+	var b strings.Builder
+	for i, c := range p.Cells {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		fmt.Fprintf(&b, "%d,%d", c.X, c.Y)
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package pattern
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// sameCells tells if a and b hold the same cells, in any order
+func sameCells(a, b []engine.Coord) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	engine.SortCoords(a)
+	engine.SortCoords(b)
+	return slices.Equal(a, b)
+}
+
+func TestCanonical(t *testing.T) {
+	// Every rotation, reflection and translation of a pattern has to hash
+	// the same, and different patterns of the catalog differently
+	seen := make(map[uint64]string)
+	for _, name := range Names() {
+		p, _ := Named(name)
+		h := p.Hash()
+		if other, found := seen[h]; found {
+			t.Errorf("%s and %s hash the same", name, other)
+		}
+		seen[h] = name
+		q := p.Translate(engine.Coord{X: -7, Y: 3})
+		for i := 0; i < 8; i++ {
+			if i == 4 {
+				q = q.Flip()
+			}
+			if q.Hash() != h || !slices.Equal(q.Canonical().Cells, p.Canonical().Cells) {
+				t.Errorf("%s turned %d times is not canonicalized like %s", name, i%4, name)
+			}
+			q = q.Rotate()
+		}
+	}
+}
+
+func TestCoordinates(t *testing.T) {
+	p, err := ParseCoordinates("1,0;2,1;0,2;1,2;2,2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := ParseCoordinates(FormatCoordinates(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(q.Cells, p.Cells) {
+		t.Errorf("%v reads back as %v", p.Cells, q.Cells)
+	}
+	for _, s := range []string{"1", "1,x", "1,2;3"} {
+		if p, err := ParseCoordinates(s); err == nil {
+			t.Errorf("%q reads as %v, want an error", s, p.Cells)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package pattern
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// ReadRLE reads a pattern in the Run Length Encoded format used by Golly
+// and the LifeWiki. The name, comments and rule of the file end up in the
+// pattern. Rows of the file run downwards, so the cell in row r, column c
+// is at x = c, y = -r, offset by a #R or #P line if there is one, whose y
+// runs downwards too. Of the
+// states of a multi-state pattern, A to X and pA to yO, state 1 is alive
+// and the states from 2 on are the decay states of a Generations rule.
+func ReadRLE(r io.Reader) (Pattern, error) {
+	var p Pattern
+	var offset engine.Coord
+	var comments []string
+	header := false
+	x, y := 0, 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if !header {
+			if strings.HasPrefix(line, "#") {
+				tag, text := rleComment(line)
+				switch tag {
+				case "N":
+					p.Name = text
+				case "C", "c", "O":
+					comments = append(comments, text)
+				case "R", "P":
+					if _, err := fmt.Sscanf(text, "%d %d", &offset.X, &offset.Y); err != nil {
+						return p, fmt.Errorf("rle line %d: invalid offset %q", lineNo, text)
+					}
+				}
+				continue
+			}
+
+			rule, err := rleHeader(line)
+			if err != nil {
+				return p, fmt.Errorf("rle line %d: %v", lineNo, err)
+			}
+			p.Rule = rule
+			header = true
+			continue
+		}
+
+		n := 0
+		var prefix rune // p to y, the first letter of a state from 25 on
+		for _, ch := range line {
+			switch {
+			case ch >= '0' && ch <= '9' && prefix == 0:
+				n = 10*n + int(ch-'0')
+				continue
+			case (ch == ' ' || ch == '\t') && prefix == 0:
+				continue
+			case ch == '!' && prefix == 0:
+				p.Comment = strings.Join(comments, "\n")
+				return p.upsideDown(), scanner.Err()
+			case ch >= 'p' && ch <= 'y' && prefix == 0:
+				prefix = ch
+				continue
+			}
+
+			if n == 0 {
+				n = 1
+			}
+			state := 0
+			switch {
+			case prefix != 0 && ch >= 'A' && ch <= 'X' && int(prefix-'p'+1)*24+int(ch-'A'+1) <= 255:
+				state = int(prefix-'p'+1)*24 + int(ch-'A'+1)
+			case prefix != 0:
+				return p, fmt.Errorf("rle line %d: invalid state %q", lineNo, string(prefix)+string(ch))
+			case ch == 'b' || ch == '.':
+			case ch == '$':
+				x = 0
+				y += n
+				n = 0
+				continue
+			case ch == 'o':
+				state = 1
+			case ch >= 'A' && ch <= 'X':
+				state = int(ch - 'A' + 1)
+			default:
+				return p, fmt.Errorf("rle line %d: invalid state %q", lineNo, ch)
+			}
+			for i := 0; i < n && state > 0; i++ {
+				c := engine.Coord{X: offset.X + x + i, Y: offset.Y + y}
+				if state == 1 {
+					p.Cells = append(p.Cells, c)
+					continue
+				}
+				if p.Decaying == nil {
+					p.Decaying = make(map[engine.Coord]uint8)
+				}
+				p.Decaying[c] = uint8(state - 1)
+			}
+			x += n
+			n, prefix = 0, 0
+		}
+		if prefix != 0 {
+			return p, fmt.Errorf("rle line %d: state %q cut off at the end of the line", lineNo, prefix)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return p, err
+	}
+	if !header {
+		return p, fmt.Errorf("rle: missing header line")
+	}
+
+	// Some files in the wild are missing the final !
+	p.Comment = strings.Join(comments, "\n")
+	return p.upsideDown(), nil
+}
+
+// ParseRLE reads a pattern in RLE format and returns it as a world
+func ParseRLE(r io.Reader) (engine.World, error) {
+	p, err := ReadRLE(r)
+	if err != nil {
+		return nil, err
+	}
+	return p.World(), nil
+}
+
+// rleLineLength is the longest line WriteRLE writes, as Golly does
+const rleLineLength = 70
+
+// WriteRLE writes the pattern in RLE format, with the top left corner of
+// its bounding box in the first column of the first row. A pattern with
+// decaying cells is written with the letters of the states, as ReadRLE
+// reads them: . for dead, A for alive and B on for the decay states.
+func WriteRLE(w io.Writer, p Pattern) error {
+	bw := bufio.NewWriter(w)
+	if p.Name != "" {
+		fmt.Fprintf(bw, "#N %s\n", p.Name)
+	}
+	if p.Comment != "" {
+		for _, line := range strings.Split(p.Comment, "\n") {
+			fmt.Fprintf(bw, "#C %s\n", line)
+		}
+	}
+
+	// In the order of the rows, from the top down
+	p = p.upsideDown()
+	lo, hi := p.extent()
+	q := p.Translate(engine.Coord{X: -lo.X, Y: -lo.Y})
+	if len(q.Cells) == 0 && len(q.Decaying) == 0 {
+		lo, hi = engine.Coord{}, engine.Coord{X: -1, Y: -1}
+	}
+	fmt.Fprintf(bw, "x = %d, y = %d", hi.X-lo.X+1, hi.Y-lo.Y+1)
+	if p.Rule != "" {
+		fmt.Fprintf(bw, ", rule = %s", p.Rule)
+	}
+	bw.WriteByte('\n')
+
+	// The cells in the order of the rows, with their states
+	cells := slices.Clone(q.Cells)
+	dead, state := "b", func(engine.Coord) string { return "o" }
+	if len(q.Decaying) > 0 {
+		alive := make(map[engine.Coord]bool, len(q.Cells))
+		for _, c := range q.Cells {
+			alive[c] = true
+		}
+		for c := range q.Decaying {
+			if !alive[c] {
+				cells = append(cells, c)
+			}
+		}
+		dead, state = ".", func(c engine.Coord) string {
+			if alive[c] {
+				return "A"
+			}
+			return rleState(int(q.Decaying[c]) + 1)
+		}
+	}
+	engine.SortCoords(cells)
+
+	// The runs are wrapped into lines, never breaking a run apart
+	line := 0
+	run := func(n int, tag string) {
+		s := tag
+		if n > 1 {
+			s = strconv.Itoa(n) + s
+		}
+		if line+len(s) > rleLineLength {
+			bw.WriteByte('\n')
+			line = 0
+		}
+		bw.WriteString(s)
+		line += len(s)
+	}
+
+	x, y := 0, 0
+	for i := 0; i < len(cells); {
+		c := cells[i]
+		if c.Y == y && c.X < x {
+			// A cell listed twice
+			i++
+			continue
+		}
+		if c.Y > y {
+			run(c.Y-y, "$")
+			x, y = 0, c.Y
+		}
+		if c.X > x {
+			run(c.X-x, dead)
+		}
+		tag := state(c)
+		n := 1
+		for i+n < len(cells) && cells[i+n] == (engine.Coord{X: c.X + n, Y: c.Y}) && state(cells[i+n]) == tag {
+			n++
+		}
+		run(n, tag)
+		x, i = c.X+n, i+n
+	}
+	run(1, "!")
+	bw.WriteByte('\n')
+	return bw.Flush()
+}
+
+// rleState returns the letters of state n of a multi-state pattern: A to
+// X for the states 1 to 24, pA to yO for the states from 25 on
+func rleState(n int) string {
+	if n <= 24 {
+		return string(rune('A' + n - 1))
+	}
+	prefix := (n - 1) / 24
+	return string(rune('p'+prefix-1)) + string(rune('A'+n-prefix*24-1))
+}
+
+// rleComment splits a # line into its tag and text
+func rleComment(line string) (tag, text string) {
+	line = strings.TrimPrefix(line, "#")
+	if line == "" {
+		return "", ""
+	}
+	return line[:1], strings.TrimSpace(line[1:])
+}
+
+// rleHeader checks the x = m, y = n[, rule = r] header line and returns
+// the rule
+func rleHeader(line string) (rule string, err error) {
+	seen := map[string]bool{}
+	for _, part := range strings.Split(line, ",") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			return "", fmt.Errorf("invalid header %q", line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "x", "y":
+			if _, err := strconv.Atoi(value); err != nil {
+				return "", fmt.Errorf("invalid %s in header %q", key, line)
+			}
+		case "rule":
+			rule = value
+		}
+		seen[key] = true
+	}
+	if !seen["x"] || !seen["y"] {
+		return "", fmt.Errorf("header %q lacks x or y", line)
+	}
+	return rule, nil
+}
@@ -0,0 +1,124 @@
+package pattern
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+func TestRLERoundTrip(t *testing.T) {
+	for _, name := range Names() {
+		p, _ := Named(name)
+		p.Rule = "B3/S23"
+		var b bytes.Buffer
+		if err := WriteRLE(&b, p); err != nil {
+			t.Fatal(err)
+		}
+		q, err := ReadRLE(&b)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if q.Name != p.Name || q.Comment != p.Comment || q.Rule != p.Rule {
+			t.Errorf("%s reads back as %q, %q, rule %q", name, q.Name, q.Comment, q.Rule)
+		}
+		if !sameCells(q.Normalize().Cells, p.Normalize().Cells) {
+			t.Errorf("%s of %d cells reads back as %d cells", name, len(p.Cells), len(q.Cells))
+		}
+	}
+}
+
+func TestReadRLE(t *testing.T) {
+	p, err := ReadRLE(strings.NewReader("#N glider\n#C moves\n#P 10 20\nx = 3, y = 3, rule = B3/S23\nbo$2bo$\n3o!\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "glider" || p.Comment != "moves" || p.Rule != "B3/S23" {
+		t.Errorf("glider reads as %q, %q, rule %q", p.Name, p.Comment, p.Rule)
+	}
+	// The rows and the y of #P run downwards
+	want := []engine.Coord{{X: 11, Y: -20}, {X: 12, Y: -21}, {X: 10, Y: -22}, {X: 11, Y: -22}, {X: 12, Y: -22}}
+	if !sameCells(p.Cells, want) {
+		t.Errorf("glider reads as %v, want %v", p.Cells, want)
+	}
+
+	// The spaceship of Brian's Brain reads as states A, alive, and B,
+	// decaying; states from 25 on have a prefix letter
+	p, err = ReadRLE(strings.NewReader("x = 3, y = 2, rule = /2/256\nBA$BApA!\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameCells(p.Cells, []engine.Coord{{X: 1, Y: 0}, {X: 1, Y: -1}}) {
+		t.Errorf("live cells %v, want 1,0 and 1,-1", p.Cells)
+	}
+	if len(p.Decaying) != 3 || p.Decaying[engine.Coord{X: 0, Y: 0}] != 1 || p.Decaying[engine.Coord{X: 0, Y: -1}] != 1 || p.Decaying[engine.Coord{X: 2, Y: -1}] != 24 {
+		t.Errorf("decaying cells %v, want 0,0 and 0,-1 in state 1 and 2,-1 in state 24", p.Decaying)
+	}
+
+	// Some files in the wild are missing the final !
+	if p, err := ReadRLE(strings.NewReader("x = 2, y = 1\n2o\n")); err != nil || len(p.Cells) != 2 {
+		t.Errorf("RLE without ! reads as %v, %v", p.Cells, err)
+	}
+}
+
+func TestReadRLEErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"bo$2bo$3o!",
+		"x = 3\nbo!",
+		"x = 3, y\nbo!",
+		"x = a, y = 3\nbo!",
+		"#P 1 x\nx = 1, y = 1\no!",
+		"x = 1, y = 1\nz!",
+		"x = 1, y = 1\npZ!",
+		"x = 1, y = 1\nyP!",
+		"x = 1, y = 1\n2p\n",
+	} {
+		if p, err := ReadRLE(strings.NewReader(in)); err == nil {
+			t.Errorf("%q reads as %v, want an error", in, p.Cells)
+		}
+	}
+}
+
+func TestRLEDecayingRoundTrip(t *testing.T) {
+	// Every state letter, from B for the first decay state to yO for the
+	// last one, and a decaying cell outside the bounding box of the live
+	// ones
+	p := Pattern{Rule: "3/2/256", Cells: []engine.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}}, Decaying: map[engine.Coord]uint8{}}
+	for s := 1; s <= 254; s++ {
+		p.Decaying[engine.Coord{X: s % 40, Y: 1 + s/40}] = uint8(s)
+	}
+	p.Decaying[engine.Coord{X: -3, Y: -2}] = 7
+	var b bytes.Buffer
+	if err := WriteRLE(&b, p); err != nil {
+		t.Fatal(err)
+	}
+	q, err := ReadRLE(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The top left corner of the cells comes back at the origin
+	lo, hi := p.extent()
+	want := p.Translate(engine.Coord{X: -lo.X, Y: -hi.Y})
+	if !sameCells(q.Cells, want.Cells) {
+		t.Errorf("live cells read back as %v, want %v", q.Cells, want.Cells)
+	}
+	if len(q.Decaying) != len(want.Decaying) {
+		t.Fatalf("%d decaying cells read back as %d", len(want.Decaying), len(q.Decaying))
+	}
+	for c, s := range want.Decaying {
+		if q.Decaying[c] != s {
+			t.Errorf("cell %d,%d in decay state %d reads back in state %d", c.X, c.Y, s, q.Decaying[c])
+		}
+	}
+}
+
+func TestRLEState(t *testing.T) {
+	for n, want := range map[int]string{1: "A", 2: "B", 24: "X", 25: "pA", 48: "pX", 49: "qA", 255: "yO"} {
+		if s := rleState(n); s != want {
+			t.Errorf("state %d is written as %s, want %s", n, s, want)
+		}
+	}
+}
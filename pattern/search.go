@@ -0,0 +1,50 @@
+package pattern
+
+import (
+	"slices"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// Find returns the places where p contains the sub-pattern in any of its
+// orientations: the lower left corners of the bounding boxes of the
+// matching orientations, in the coordinates of p, sorted and each place
+// once. A match needs the live cells of the sub-pattern to be alive in p;
+// with exact, all other cells of its bounding box and of the ring of cells
+// around the box have to be dead in p, so that the sub-pattern stands on
+// its own instead of being part of a larger object.
+func (p Pattern) Find(sub Pattern, exact bool) []engine.Coord {
+	if len(sub.Cells) == 0 {
+		return nil
+	}
+	var found []engine.Coord
+	for _, m := range NewMatcher(sub, exact).Next(p.World()) {
+		found = append(found, m.At)
+	}
+	engine.SortCoords(found)
+	return slices.Compact(found)
+}
+
+// matches tells whether the normalized orientation o of a sub-pattern,
+// with its bounding box from the origin to max, is alive in the cells at
+// offset at
+func matches(alive map[engine.Coord]bool, o Pattern, at, max engine.Coord, exact bool) bool {
+	for _, c := range o.Cells {
+		if !alive[engine.Coord{X: at.X + c.X, Y: at.Y + c.Y}] {
+			return false
+		}
+	}
+	if !exact {
+		return true
+	}
+	n := 0
+	for x := -1; x <= max.X+1; x++ {
+		for y := -1; y <= max.Y+1; y++ {
+			if alive[engine.Coord{X: at.X + x, Y: at.Y + y}] {
+				n++
+			}
+		}
+	}
+	// The live cells of o are among them already
+	return n == len(o.Cells)
+}
@@ -0,0 +1,23 @@
+package pattern
+
+import "testing"
+
+func TestFind(t *testing.T) {
+	// Gosper's gun holds its two blocks on their own, and two more squares
+	// of live cells as part of larger objects; a glider is found in every
+	// orientation of itself
+	block, _ := Named("block")
+	gun, _ := Named("gosper-gun")
+	if n := len(gun.Find(block, true)); n != 2 {
+		t.Errorf("%d blocks standing on their own in the gun, want 2", n)
+	}
+	if n := len(gun.Find(block, false)); n != 4 {
+		t.Errorf("%d blocks in the gun, want 4", n)
+	}
+	glider, _ := Named("glider")
+	for _, o := range glider.Orientations() {
+		if places := o.Find(glider, true); len(places) != 1 {
+			t.Errorf("glider found %d times in one of its orientations", len(places))
+		}
+	}
+}
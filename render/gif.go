@@ -0,0 +1,66 @@
+package render
+
+import (
+	"image"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// GIF draws every generation as a frame of an animated GIF. GIF
+// needs the number of frames up front, so the frames are kept in memory
+// and the file is written when the renderer is closed. With steps > 1
+// every generation is shown in that many frames, fading from the previous
+// generation to it.
+type GIF struct {
+	w      io.Writer
+	raster *Raster
+	delay  int // between frames, in hundredths of a second
+	steps  int
+	prev   engine.World
+	anim   gif.GIF
+}
+
+// NewGIF returns a GIF of the d x d view around the origin, written to w
+// when it is closed, with delay between generations
+func NewGIF(w io.Writer, d int, opts Options, delay time.Duration, steps int) *GIF {
+	if steps < 1 {
+		steps = 1
+	}
+	r := &GIF{w: w, raster: NewRaster(d, opts), steps: steps}
+	r.delay = int(delay / time.Duration(steps) / (10 * time.Millisecond))
+	if r.delay < 1 {
+		// Browsers slow anything faster than this down to 1/10th second
+		r.delay = 2
+	}
+	n := r.raster.Size()
+	r.anim.Config = image.Config{ColorModel: r.raster.pal, Width: n, Height: n}
+	return r
+}
+
+func (r *GIF) Render(world engine.World, gen int) error {
+	if r.prev != nil {
+		for k := 1; k < r.steps; k++ {
+			r.add(r.raster.DrawTransition(r.prev, world, float64(k)/float64(r.steps)))
+		}
+	}
+	r.add(r.raster.Draw(world))
+	if r.steps > 1 {
+		r.prev = world
+	}
+	return nil
+}
+
+func (r *GIF) add(img *image.Paletted) {
+	r.anim.Image = append(r.anim.Image, img)
+	r.anim.Delay = append(r.anim.Delay, r.delay)
+}
+
+func (r *GIF) Close() error {
+	if len(r.anim.Image) == 0 {
+		return nil
+	}
+	return gif.EncodeAll(r.w, &r.anim)
+}
@@ -0,0 +1,181 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// gnuplot has no notion of pixels, so the cell gap is converted to axis
+// units assuming a cell is this many pixels wide
+const gnuplotCellPixels = 10
+
+// Gnuplot writes the generations as a gnuplot script, one plot command
+// per generation
+type Gnuplot struct {
+	w    *bufio.Writer
+	opts Options
+}
+
+// NewGnuplot writes the run metadata as comments and the header for a
+// view of d x d cells around the origin
+func NewGnuplot(w io.Writer, d int, opts Options, meta Metadata) *Gnuplot {
+	r := &Gnuplot{bufio.NewWriter(w), opts}
+
+	for _, m := range meta {
+		fmt.Fprintf(r.w, "# %s=%s\n", m.Key, m.Value)
+	}
+	r.header(d)
+
+	return r
+}
+
+// header prints the header for gnuplot
+func (r *Gnuplot) header(d int) {
+	w, opts := r.w, r.opts
+
+	fmt.Fprintf(w, "unset key; set xrange[-%[1]d:%[1]d]\n", d/2)
+	fmt.Fprintf(w, "set yrange[-%[1]d:%[1]d]\n", d/2)
+	if opts.Shape == ShapePoint {
+		fmt.Fprintf(w, "set style line 1 lc rgb '%s' pt 7\n", opts.Theme.Cell)
+	} else {
+		fmt.Fprintf(w, "set style line 1 lc rgb '%s'\n", opts.Theme.Cell)
+	}
+	fmt.Fprintln(w, "set style fill solid noborder")
+	fmt.Fprintf(w, "set object 1 rectangle from screen 0,0 to screen 1,1 fillcolor rgb '%s' behind\n", opts.Theme.Background)
+
+	if opts.Axis {
+		fmt.Fprintf(w, "set border lc rgb '%[1]s'; set tics textcolor rgb '%[1]s'\n", opts.Theme.Axis)
+	} else {
+		fmt.Fprintln(w, "unset border; unset xtics; unset ytics")
+	}
+
+	if opts.Grid > 0 {
+		// The tics carry the grid lines, so we need them even without an axis
+		fmt.Fprintf(w, "set xtics %[1]d; set ytics %[1]d\n", opts.Grid)
+		fmt.Fprintf(w, "set grid xtics ytics lc rgb '%s'\n", opts.Theme.Grid)
+		if !opts.Axis {
+			fmt.Fprintln(w, "set xtics format ''; set ytics format ''; set tics scale 0")
+		}
+	}
+
+	if opts.Bin > 1 {
+		// Blend from the background to the cell color by the number of
+		// live cells in a bin
+		fmt.Fprintf(w, "set palette defined (0 '%s', 1 '%s')\n", opts.Theme.Background, opts.Theme.Cell)
+		fmt.Fprintf(w, "set cbrange [0:%d]; unset colorbox\n", opts.Bin*opts.Bin)
+	} else if opts.Ages {
+		// Blend from the origin color for newborn cells to the cell color
+		// for old ones
+		fmt.Fprintf(w, "set palette defined (0 '%s', 1 '%s')\n", opts.Theme.Origin, opts.Theme.Cell)
+		fmt.Fprintf(w, "set cbrange [0:%d]; unset colorbox\n", AgeShades)
+	}
+
+	if opts.Origin {
+		fmt.Fprintf(w, "set label 1 '' at 0,0 point pt 2 ps 2 lc rgb '%s' front\n", opts.Theme.Origin)
+	}
+}
+
+// Render prints the plot command for a generation, and flushes it so
+// gnuplot can draw it right away
+func (r *Gnuplot) Render(world engine.World, gen int) error {
+	if r.opts.Bin > 1 {
+		r.density(world)
+	} else {
+		r.cells(world)
+	}
+	return r.w.Flush()
+}
+
+func (r *Gnuplot) Close() error {
+	return r.w.Flush()
+}
+
+// cells prints the coordinates of the cells in the world
+func (r *Gnuplot) cells(world engine.World) {
+	// Half the width of a cell, less the gap shared with the neighbours
+	h := 0.5 - float64(r.opts.Gap)/(2*gnuplotCellPixels)
+	if h < 0.05 {
+		h = 0.05
+	}
+
+	// Colored by age, the third column is the age of the cell. Under a
+	// Generations rule it is the color of every cell instead, as the
+	// decaying cells are drawn as well.
+	color, age := "ls 1", ""
+	switch {
+	case r.opts.States > 2:
+		color, age = "fc rgb variable", ":3"
+	case r.opts.Ages:
+		color, age = "fc palette", ":3"
+	}
+	switch r.opts.Shape {
+	case ShapePoint:
+		// Points keep their size in pixels, so the gap does not matter
+		if age == "" {
+			fmt.Fprintln(r.w, "plot '-' with points ls 1")
+		} else {
+			fmt.Fprintf(r.w, "plot '-' using 1:2%s with points pt 7 %s\n", age, strings.Replace(color, "fc", "lc", 1))
+		}
+	case ShapeCircle:
+		fmt.Fprintf(r.w, "plot '-' using 1:2:(%g)%s with circles %s\n", h, age, color)
+	default:
+		// gnuplot cannot round the corners of a box, rounded cells are
+		// drawn as squares
+		fmt.Fprintf(r.w, "plot '-' using 1:2:(%[1]g):(%[1]g)%s with boxxyerror %s\n", h, age, color)
+	}
+
+	if r.opts.States > 2 {
+		t := r.opts.Theme
+		cell := ParseColor(t.Cell)
+		for _, coord := range world.LiveCells() {
+			if r.opts.Ages {
+				cell = AgeColor(t, world[coord].Age)
+			}
+			fmt.Fprintf(r.w, "%d, %d, %d\n", coord.X, coord.Y, gnuplotRGB(cell))
+		}
+		for _, coord := range DecayingCells(world) {
+			fmt.Fprintf(r.w, "%d, %d, %d\n", coord.X, coord.Y, gnuplotRGB(DecayColor(t, world[coord].State, r.opts.States)))
+		}
+		fmt.Fprintln(r.w, "e")
+		return
+	}
+	for _, coord := range world.LiveCells() {
+		if r.opts.Ages {
+			fmt.Fprintf(r.w, "%d, %d, %d\n", coord.X, coord.Y, min(world[coord].Age, AgeShades))
+		} else {
+			fmt.Fprintf(r.w, "%d, %d\n", coord.X, coord.Y)
+		}
+	}
+
+	fmt.Fprintln(r.w, "e")
+}
+
+// gnuplotRGB returns a color as the number gnuplot expects for rgb variable
+func gnuplotRGB(c color.RGBA) int {
+	return int(c.R)<<16 | int(c.G)<<8 | int(c.B)
+}
+
+// density prints the bins of a zoomed out world shaded by the number of
+// live cells they contain
+func (r *Gnuplot) density(world engine.World) {
+	bin := r.opts.Bin
+	h := float64(bin) / 2
+	fmt.Fprintf(r.w, "plot '-' using 1:2:(%[1]g):(%[1]g):3 with boxxyerror fc palette\n", h)
+
+	bins := densityBins(world, bin)
+	keys := make([]engine.Coord, 0, len(bins))
+	for coord := range bins {
+		keys = append(keys, coord)
+	}
+	engine.SortCoords(keys)
+	for _, coord := range keys {
+		fmt.Fprintf(r.w, "%g, %g, %d\n", float64(coord.X*bin)+h-0.5, float64(coord.Y*bin)+h-0.5, bins[coord])
+	}
+
+	fmt.Fprintln(r.w, "e")
+}
@@ -0,0 +1,46 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/miromotl/gol/engine"
+)
+
+func TestGnuplotDeterministic(t *testing.T) {
+	// Rendering the same world twice must give the same bytes, no matter
+	// in which order the map hands out its cells
+	world := make(engine.World)
+	engine.FillRandomSoup(world, 1, 64, 1)
+	world = world.Tick()
+	for _, opts := range []Options{
+		{Theme: Themes["classic"]},
+		{Theme: Themes["classic"], Shape: ShapePoint},
+		{Theme: Themes["classic"], Ages: true},
+		{Theme: Themes["classic"], Bin: 4},
+	} {
+		var a, b bytes.Buffer
+		for _, buf := range []*bytes.Buffer{&a, &b} {
+			r := NewGnuplot(buf, 64, opts, nil)
+			r.Render(world, 1)
+			r.Close()
+		}
+		if !bytes.Equal(a.Bytes(), b.Bytes()) {
+			t.Errorf("gnuplot output differs between two renderings, options %+v", opts)
+		}
+	}
+}
+
+func TestGnuplotPoints(t *testing.T) {
+	// Cells drawn as points keep the style and plot command of the first
+	// versions, which scripts reading the output may rely on
+	var b bytes.Buffer
+	r := NewGnuplot(&b, 50, Options{Theme: Themes["classic"], Shape: ShapePoint}, nil)
+	r.Render(engine.World{{X: 1, Y: 2}: {Alive: true}}, 0)
+	r.Close()
+	for _, line := range []string{"set style line 1 lc rgb '#0060ad' pt 7\n", "plot '-' with points ls 1\n1, 2\ne\n"} {
+		if !bytes.Contains(b.Bytes(), []byte(line)) {
+			t.Errorf("gnuplot output lacks %q:\n%s", line, b.String())
+		}
+	}
+}
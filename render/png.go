@@ -0,0 +1,50 @@
+package render
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// PNG writes every generation to its own PNG file. The path is a
+// format with a %d verb for the generation, e.g. frame_%04d.png, so the
+// frames can be put together with ffmpeg -i frame_%04d.png.
+type PNG struct {
+	path   string
+	raster *Raster
+}
+
+// CheckFramePath tells whether path has the single verb for the generation
+// a path of PNG frames needs
+func CheckFramePath(path string) error {
+	a, b := fmt.Sprintf(path, 1), fmt.Sprintf(path, 2)
+	if a == b || strings.Contains(a, "%!") {
+		return fmt.Errorf("the path %q of the PNG frames needs one %%d for the generation, e.g. frame_%%04d.png", path)
+	}
+	return nil
+}
+
+// NewPNG returns a PNG writing the frames of the d x d view around the
+// origin to path
+func NewPNG(path string, d int, opts Options) *PNG {
+	return &PNG{path, NewRaster(d, opts)}
+}
+
+func (r *PNG) Render(world engine.World, gen int) error {
+	f, err := os.Create(fmt.Sprintf(r.path, gen))
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, r.raster.Draw(world)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (r *PNG) Close() error {
+	return nil
+}
@@ -0,0 +1,313 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// Palette indices of the colors of a theme in a rastered frame. The
+// shades used for zoomed out views follow the theme colors.
+const (
+	rasterBackground = iota
+	rasterGrid
+	rasterAxis
+	rasterOrigin
+	rasterCell
+	rasterShades
+)
+
+// densityShades is the number of shades between the background and the
+// cell color in a zoomed out raster
+const densityShades = 16
+
+// AgeShades is the number of colors cells go through as they age, from
+// the origin color of the theme when they are born to the cell color. Cells
+// older than that, like still lifes and ash, are drawn in the cell color.
+const AgeShades = 16
+
+// A Raster draws the d x d view around the origin into paletted images,
+// for the image based renderers. Like gnuplot it puts the largest y at the
+// top.
+type Raster struct {
+	h     int // the view runs from -h to h in both directions
+	scale int // pixels per cell
+	opts  Options
+	mask  []bool // pixels of a cell covered by a live cell, scale x scale
+	pal   color.Palette
+}
+
+// NewRaster returns a raster of the d x d view around the origin
+func NewRaster(d int, opts Options) *Raster {
+	r := &Raster{h: d / 2, scale: opts.Scale, opts: opts}
+	if r.scale < 1 {
+		r.scale = 1
+	}
+	r.mask = cellMask(r.scale, opts.Gap, opts.Shape)
+
+	t := opts.Theme
+	bg, cell := ParseColor(t.Background), ParseColor(t.Cell)
+	r.pal = color.Palette{bg, ParseColor(t.Grid), ParseColor(t.Axis), ParseColor(t.Origin), cell}
+	for k := 1; k <= densityShades; k++ {
+		r.pal = append(r.pal, Blend(bg, cell, float64(k)/densityShades))
+	}
+	if opts.Ages {
+		for age := 0; age < AgeShades; age++ {
+			r.pal = append(r.pal, AgeColor(t, age))
+		}
+	}
+	return r
+}
+
+// Size is the width and height of a frame in pixels
+func (r *Raster) Size() int {
+	return (2*r.h + 1) * r.scale
+}
+
+// Draw renders the world into a new frame
+func (r *Raster) Draw(world engine.World) *image.Paletted {
+	img := r.frame()
+	if r.opts.Bin > 1 {
+		r.density(img, world)
+	} else {
+		for coord, cell := range world {
+			if !r.visible(coord) {
+				continue
+			}
+			if cell.Alive {
+				r.cell(img, coord, r.ageShade(cell.Age))
+			} else if s := r.shade(r.level(cell)); s != rasterBackground {
+				r.cell(img, coord, s)
+			}
+		}
+	}
+	r.decorate(img)
+	return img
+}
+
+// DrawTransition renders a frame between the generations prev and next,
+// with t running from 0 at prev to 1 at next. Cells born in next fade in,
+// cells dying fade out, and decaying cells fade from one decay state to
+// the next. Zoomed out views have no cells to fade and show next right
+// away.
+func (r *Raster) DrawTransition(prev, next engine.World, t float64) *image.Paletted {
+	if r.opts.Bin > 1 {
+		return r.Draw(next)
+	}
+
+	img := r.frame()
+	for coord, cell := range next {
+		to := r.level(cell)
+		if to == 0 || !r.visible(coord) {
+			continue
+		}
+		if cell.Alive && prev[coord].Alive {
+			r.cell(img, coord, r.ageShade(cell.Age))
+			continue
+		}
+		from := r.level(prev[coord])
+		if s := r.shade(from + t*(to-from)); s != rasterBackground {
+			r.cell(img, coord, s)
+		}
+	}
+	for coord, cell := range prev {
+		from := r.level(cell)
+		if from == 0 || r.level(next[coord]) > 0 || !r.visible(coord) {
+			continue
+		}
+		if s := r.shade((1 - t) * from); s != rasterBackground {
+			r.cell(img, coord, s)
+		}
+	}
+	r.decorate(img)
+	return img
+}
+
+// level is how far the color of a cell is from the background to the cell
+// color: 1 for a live cell, less with every decay state of a Generations
+// rule, and 0 for a dead cell
+func (r *Raster) level(cell engine.Cell) float64 {
+	switch {
+	case cell.Alive:
+		return 1
+	case cell.State == 0 || r.opts.States < 3:
+		return 0
+	}
+	return 1 - float64(cell.State)/float64(r.opts.States-1)
+}
+
+// shade returns the palette index of the color a fraction t of the way
+// from the background to the cell color
+func (r *Raster) shade(t float64) uint8 {
+	k := int(t*densityShades + 0.5)
+	if k <= 0 {
+		return rasterBackground
+	}
+	if k > densityShades {
+		k = densityShades
+	}
+	return uint8(rasterShades + k - 1)
+}
+
+// ageShade returns the palette index of the color of a live cell of the
+// given age, which is the cell color unless cells are colored by age
+func (r *Raster) ageShade(age int) uint8 {
+	if !r.opts.Ages || age >= AgeShades {
+		return rasterCell
+	}
+	return uint8(rasterShades + densityShades + age)
+}
+
+// frame returns an empty frame with the grid drawn on it
+func (r *Raster) frame() *image.Paletted {
+	n := r.Size()
+	img := image.NewPaletted(image.Rect(0, 0, n, n), r.pal)
+
+	if g := r.opts.Grid; g > 0 {
+		// Grid lines run along the left and top edge of every cell whose
+		// coordinate is a multiple of the grid spacing
+		for x := -r.h; x <= r.h; x++ {
+			if x%g == 0 {
+				r.fill(img, (x+r.h)*r.scale, 0, 1, n, rasterGrid)
+			}
+		}
+		for y := -r.h; y <= r.h; y++ {
+			if y%g == 0 {
+				r.fill(img, 0, (r.h-y)*r.scale, n, 1, rasterGrid)
+			}
+		}
+	}
+	return img
+}
+
+// decorate draws the origin mark and the axis border over the cells
+func (r *Raster) decorate(img *image.Paletted) {
+	n := r.Size()
+	if r.opts.Origin {
+		// A cross over the origin, like the point gnuplot draws
+		x0, y0 := r.h*r.scale, r.h*r.scale
+		for i := 0; i < r.scale; i++ {
+			img.SetColorIndex(x0+i, y0+i, rasterOrigin)
+			img.SetColorIndex(x0+r.scale-1-i, y0+i, rasterOrigin)
+		}
+	}
+
+	if r.opts.Axis {
+		r.fill(img, 0, 0, n, 1, rasterAxis)
+		r.fill(img, 0, n-1, n, 1, rasterAxis)
+		r.fill(img, 0, 0, 1, n, rasterAxis)
+		r.fill(img, n-1, 0, 1, n, rasterAxis)
+	}
+}
+
+func (r *Raster) visible(c engine.Coord) bool {
+	return c.X >= -r.h && c.X <= r.h && c.Y >= -r.h && c.Y <= r.h
+}
+
+// cell draws a live cell in the shape of the mask and the color at index
+func (r *Raster) cell(img *image.Paletted, c engine.Coord, index uint8) {
+	x0, y0 := (c.X+r.h)*r.scale, (r.h-c.Y)*r.scale
+	for i, on := range r.mask {
+		if on {
+			img.SetColorIndex(x0+i%r.scale, y0+i/r.scale, index)
+		}
+	}
+}
+
+// density shades every bin of a zoomed out world by the number of live
+// cells in it
+func (r *Raster) density(img *image.Paletted, world engine.World) {
+	bin := r.opts.Bin
+	for b, count := range densityBins(world, bin) {
+		shade := (count*densityShades + bin*bin - 1) / (bin * bin)
+		// The lower left cell of the bin, in the upper left corner of
+		// the picture that is its top row
+		x0 := (b.X*bin + r.h) * r.scale
+		y0 := (r.h - (b.Y*bin + bin - 1)) * r.scale
+		r.fill(img, x0, y0, bin*r.scale, bin*r.scale, uint8(rasterShades+shade-1))
+	}
+}
+
+// fill paints a rectangle, clipped to the image
+func (r *Raster) fill(img *image.Paletted, x, y, w, h int, index uint8) {
+	rect := image.Rect(x, y, x+w, y+h).Intersect(img.Rect)
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		for px := rect.Min.X; px < rect.Max.X; px++ {
+			img.SetColorIndex(px, py, index)
+		}
+	}
+}
+
+// cellMask returns the pixels of a scale x scale cell that are covered by
+// a live cell of the given shape, leaving gap pixels to the neighbours
+func cellMask(scale, gap int, shape CellShape) []bool {
+	size := scale - gap
+	if size < 1 {
+		size = 1
+	}
+	off := (scale - size) / 2
+	// Distance from the center within which the pixel is covered, and the
+	// radius of the rounded corners
+	c := float64(size-1) / 2
+	radius := float64(size) / 2
+	corner := float64(size) / 4
+
+	mask := make([]bool, scale*scale)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := abs(float64(x)-c), abs(float64(y)-c)
+			on := true
+			switch shape {
+			case ShapeCircle, ShapePoint:
+				on = dx*dx+dy*dy <= radius*radius
+			case ShapeRounded:
+				// Only the corners are cut off
+				ex, ey := dx-(c-corner), dy-(c-corner)
+				on = ex <= 0 || ey <= 0 || ex*ex+ey*ey <= corner*corner
+			}
+			mask[(y+off)*scale+x+off] = on
+		}
+	}
+	return mask
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// ParseColor converts a #rrggbb color of a theme
+func ParseColor(s string) color.RGBA {
+	v, _ := strconv.ParseUint(s[1:], 16, 32)
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+}
+
+// Blend mixes the colors a and b, t = 0 is a and t = 1 is b
+func Blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + t*(float64(y)-float64(x)) + 0.5)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xff}
+}
+
+// ColorHex formats a color as #rrggbb, like the colors of a theme
+func ColorHex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// DecayColor returns the color of a cell in the given decay state of a
+// Generations rule with the given number of states, fading from the cell
+// color of the theme to the background
+func DecayColor(t Theme, state uint8, states int) color.RGBA {
+	return Blend(ParseColor(t.Cell), ParseColor(t.Background), float64(state)/float64(states-1))
+}
+
+// AgeColor returns the color of a live cell of the given age in a theme
+func AgeColor(t Theme, age int) color.RGBA {
+	return Blend(ParseColor(t.Origin), ParseColor(t.Cell), float64(min(age, AgeShades))/AgeShades)
+}
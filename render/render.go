@@ -0,0 +1,99 @@
+// Package render draws the generations of a Game Of Life world as gnuplot
+// scripts, images and SVG documents, in the colors of a theme
+package render
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// A Renderer draws the generations of a run. Render is called for every
+// generation, Close once after the last one.
+type Renderer interface {
+	Render(world engine.World, gen int) error
+	Close() error
+}
+
+// Options are the decorations drawn around the cells of the world.
+// The zero value draws nothing but the cells themselves.
+type Options struct {
+	Grid   int       // spacing of the grid lines in cells, 0 means no grid
+	Axis   bool      // draw the border and the axis ticks
+	Origin bool      // mark the cell at (0, 0)
+	Theme  Theme     // colors of the cells and decorations
+	Shape  CellShape // glyph drawn for a live cell
+	Gap    int       // empty pixels between neighbouring cells
+	Bin    int       // when > 1, bin x bin cells are drawn as one dot shaded by density
+	Scale  int       // pixels per cell in image outputs
+	Ages   bool      // color live cells by age instead of in the cell color
+	States int       // of a Generations rule, whose decaying cells fade out; 0 for a life-like rule
+}
+
+// CellShape is the glyph drawn for a single live cell
+type CellShape int
+
+const (
+	ShapeSquare CellShape = iota
+	ShapeCircle
+	ShapeRounded
+	ShapePoint // a circle, drawn with the points of gnuplot in the gnuplot output
+)
+
+var cellShapeNames = []string{"square", "circle", "rounded", "point"}
+
+func (s CellShape) String() string {
+	return cellShapeNames[s]
+}
+
+// CellShapeNames returns the names of the cell shapes, in the order of
+// their values
+func CellShapeNames() []string {
+	return slices.Clone(cellShapeNames)
+}
+
+// ParseCellShape returns the cell shape with the given name
+func ParseCellShape(name string) (CellShape, error) {
+	for i, n := range cellShapeNames {
+		if n == name {
+			return CellShape(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown cell shape %q", name)
+}
+
+// Metadata describes the configuration of a run, in a fixed order, for
+// renderers that can record it in their output
+type Metadata []struct{ Key, Value string }
+
+// Add appends the key and its value
+func (m *Metadata) Add(key, value string) {
+	*m = append(*m, struct{ Key, Value string }{key, value})
+}
+
+// densityBins groups the live cells of the world into bin x bin squares
+// and counts the live cells in each square. The key of a square is the
+// coordinate of its lower left cell divided by bin.
+func densityBins(world engine.World, bin int) map[engine.Coord]int {
+	bins := make(map[engine.Coord]int)
+	for coord, cell := range world {
+		if cell.Alive {
+			bins[engine.Coord{X: engine.FloorDiv(coord.X, bin), Y: engine.FloorDiv(coord.Y, bin)}]++
+		}
+	}
+	return bins
+}
+
+// DecayingCells returns the coordinates of the decaying cells of a world
+// under a Generations rule, in the order of LiveCells
+func DecayingCells(world engine.World) []engine.Coord {
+	var coords []engine.Coord
+	for coord, cell := range world {
+		if cell.State > 0 {
+			coords = append(coords, coord)
+		}
+	}
+	engine.SortCoords(coords)
+	return coords
+}
@@ -0,0 +1,176 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/miromotl/gol/engine"
+)
+
+// SVG draws generations as scalable vector images, with one rect
+// per live cell, for figures that have to look sharp in print. If the
+// path contains a %d verb every generation is written to its own file,
+// otherwise only the last generation is written to w when the renderer is
+// closed. Like the raster it puts the largest y at the top.
+type SVG struct {
+	path  string
+	w     io.Writer
+	h     int // the view runs from -h to h in both directions
+	scale int // user units per cell
+	opts  Options
+	meta  Metadata
+	last  engine.World
+	gen   int
+}
+
+// NewSVG returns an SVG of the d x d view around the origin, writing to
+// path or, without a %d verb in it, to w
+func NewSVG(path string, w io.Writer, d int, opts Options, meta Metadata) *SVG {
+	r := &SVG{path: path, w: w, h: d / 2, scale: opts.Scale, opts: opts, meta: meta}
+	if r.scale < 1 {
+		r.scale = 1
+	}
+	return r
+}
+
+func (r *SVG) Render(world engine.World, gen int) error {
+	if !strings.Contains(r.path, "%") {
+		r.last, r.gen = world, gen
+		return nil
+	}
+	f, err := os.Create(fmt.Sprintf(r.path, gen))
+	if err != nil {
+		return err
+	}
+	if err := r.write(f, world, gen); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (r *SVG) Close() error {
+	if r.last == nil {
+		return nil
+	}
+	return r.write(r.w, r.last, r.gen)
+}
+
+// write writes a generation as an SVG document
+func (r *SVG) write(out io.Writer, world engine.World, gen int) error {
+	w := bufio.NewWriter(out)
+	t := r.opts.Theme
+	n := (2*r.h + 1) * r.scale
+
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%[1]d\" height=\"%[1]d\" viewBox=\"0 0 %[1]d %[1]d\">\n", n)
+	fmt.Fprintf(w, "<title>generation %d</title>\n", gen)
+	if len(r.meta) > 0 {
+		fmt.Fprint(w, "<desc>")
+		for i, m := range r.meta {
+			if i > 0 {
+				fmt.Fprint(w, ", ")
+			}
+			fmt.Fprintf(w, "%s=%s", m.Key, html.EscapeString(m.Value))
+		}
+		fmt.Fprint(w, "</desc>\n")
+	}
+	fmt.Fprintf(w, "<rect width=\"%[1]d\" height=\"%[1]d\" fill=\"%s\"/>\n", n, t.Background)
+
+	if g := r.opts.Grid; g > 0 {
+		// Grid lines run along the left and top edge of every cell whose
+		// coordinate is a multiple of the grid spacing, as in the raster
+		fmt.Fprintf(w, "<g stroke=\"%s\" stroke-width=\"1\">\n", t.Grid)
+		for x := -r.h; x <= r.h; x++ {
+			if x%g == 0 {
+				fmt.Fprintf(w, "<line x1=\"%[1]d\" y1=\"0\" x2=\"%[1]d\" y2=\"%d\"/>\n", (x+r.h)*r.scale, n)
+			}
+		}
+		for y := -r.h; y <= r.h; y++ {
+			if y%g == 0 {
+				fmt.Fprintf(w, "<line x1=\"0\" y1=\"%[1]d\" x2=\"%d\" y2=\"%[1]d\"/>\n", (r.h-y)*r.scale, n)
+			}
+		}
+		fmt.Fprintln(w, "</g>")
+	}
+
+	if r.opts.Bin > 1 {
+		r.density(w, world)
+	} else {
+		r.cells(w, world)
+	}
+
+	if r.opts.Origin {
+		// A cross over the origin, like the point gnuplot draws
+		x0, y0, s := r.h*r.scale, r.h*r.scale, r.scale
+		fmt.Fprintf(w, "<path d=\"M%d %dL%d %dM%d %dL%d %d\" stroke=\"%s\" stroke-width=\"1\"/>\n",
+			x0, y0, x0+s, y0+s, x0+s, y0, x0, y0+s, t.Origin)
+	}
+	if r.opts.Axis {
+		fmt.Fprintf(w, "<rect x=\"0.5\" y=\"0.5\" width=\"%[1]d\" height=\"%[1]d\" fill=\"none\" stroke=\"%s\" stroke-width=\"1\"/>\n", n-1, t.Axis)
+	}
+
+	fmt.Fprintln(w, "</svg>")
+	return w.Flush()
+}
+
+// cells writes a rect for every visible live cell. Circles and rounded
+// cells are rects with rounded corners.
+func (r *SVG) cells(w *bufio.Writer, world engine.World) {
+	size := r.scale - r.opts.Gap
+	if size < 1 {
+		size = 1
+	}
+	off := float64(r.scale-size) / 2
+	corner := ""
+	switch r.opts.Shape {
+	case ShapeCircle, ShapePoint:
+		corner = fmt.Sprintf(" rx=\"%g\"", float64(size)/2)
+	case ShapeRounded:
+		corner = fmt.Sprintf(" rx=\"%g\"", float64(size)/4)
+	}
+
+	// The decaying cells of a Generations rule come after the live ones,
+	// each in the color of its decay state
+	cells := world.LiveCells()
+	if r.opts.States > 2 {
+		cells = append(cells, DecayingCells(world)...)
+	}
+	fmt.Fprintf(w, "<g fill=\"%s\">\n", r.opts.Theme.Cell)
+	for _, c := range cells {
+		if c.X < -r.h || c.X > r.h || c.Y < -r.h || c.Y > r.h {
+			continue
+		}
+		fill := ""
+		if cell := world[c]; !cell.Alive {
+			fill = fmt.Sprintf(" fill=\"%s\"", ColorHex(DecayColor(r.opts.Theme, cell.State, r.opts.States)))
+		} else if r.opts.Ages {
+			fill = fmt.Sprintf(" fill=\"%s\"", ColorHex(AgeColor(r.opts.Theme, cell.Age)))
+		}
+		x, y := float64((c.X+r.h)*r.scale)+off, float64((r.h-c.Y)*r.scale)+off
+		fmt.Fprintf(w, "<rect x=\"%g\" y=\"%g\" width=\"%d\" height=\"%[3]d\"%s%s/>\n", x, y, size, corner, fill)
+	}
+	fmt.Fprintln(w, "</g>")
+}
+
+// density writes a rect for every bin of a zoomed out world, shaded by
+// the number of live cells in it
+func (r *SVG) density(w *bufio.Writer, world engine.World) {
+	bin := r.opts.Bin
+	bg, cell := ParseColor(r.opts.Theme.Background), ParseColor(r.opts.Theme.Cell)
+	bins := densityBins(world, bin)
+	keys := make([]engine.Coord, 0, len(bins))
+	for b := range bins {
+		keys = append(keys, b)
+	}
+	engine.SortCoords(keys)
+	for _, b := range keys {
+		x0 := (b.X*bin + r.h) * r.scale
+		y0 := (r.h - (b.Y*bin + bin - 1)) * r.scale
+		fill := ColorHex(Blend(bg, cell, float64(bins[b])/float64(bin*bin)))
+		fmt.Fprintf(w, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%[3]d\" fill=\"%s\"/>\n", x0, y0, bin*r.scale, fill)
+	}
+}
@@ -0,0 +1,145 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// A Theme is a named set of colors used by all renderers. Colors are given
+// as '#rrggbb' strings, which every output format we have understands.
+type Theme struct {
+	Name       string
+	Background string
+	Cell       string
+	Grid       string
+	Axis       string
+	Origin     string
+}
+
+// Themes are the built-in themes, users can add their own with a theme file
+var Themes = map[string]Theme{
+	"classic":   {"classic", "#ffffff", "#0060ad", "#c0c0c0", "#000000", "#dd181f"},
+	"dark":      {"dark", "#1e1e1e", "#8ae234", "#3a3a3a", "#bbbbbb", "#ef2929"},
+	"solarized": {"solarized", "#002b36", "#268bd2", "#073642", "#93a1a1", "#dc322f"},
+	// Okabe-Ito colors, distinguishable with all common forms of color blindness
+	"colorblind": {"colorblind", "#ffffff", "#0072b2", "#bbbbbb", "#000000", "#e69f00"},
+}
+
+// ThemeNames returns the names of all known themes in alphabetical order
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadThemes reads theme definitions from the file at path and adds them
+// to the known themes
+func LoadThemes(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return ParseThemes(f)
+}
+
+// ParseThemes reads theme definitions in a small ini-like format:
+//
+//	# comments start with a hash
+//	[ocean]
+//	base = dark
+//	background = #001f3f
+//	cell = #7fdbff
+//
+// Every section defines a theme. Colors not given are taken from the base
+// theme, which defaults to classic.
+func ParseThemes(r io.Reader) error {
+	var current *Theme
+	define := func() {
+		if current != nil {
+			Themes[current.Name] = *current
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			define()
+			t := Themes["classic"]
+			t.Name = strings.TrimSpace(line[1 : len(line)-1])
+			current = &t
+			continue
+		}
+
+		if current == nil {
+			return fmt.Errorf("Theme line %d: setting outside of a [theme] section", lineNo)
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return fmt.Errorf("Theme line %d: expected key = value", lineNo)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if key == "base" {
+			base, found := Themes[value]
+			if !found {
+				return fmt.Errorf("Theme line %d: unknown base Theme %q", lineNo, value)
+			}
+			base.Name = current.Name
+			*current = base
+			continue
+		}
+
+		if !IsColor(value) {
+			return fmt.Errorf("Theme line %d: %q is not a #rrggbb color", lineNo, value)
+		}
+
+		switch key {
+		case "background":
+			current.Background = value
+		case "cell":
+			current.Cell = value
+		case "grid":
+			current.Grid = value
+		case "axis":
+			current.Axis = value
+		case "origin":
+			current.Origin = value
+		default:
+			return fmt.Errorf("Theme line %d: unknown color %q", lineNo, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	define()
+	return nil
+}
+
+// IsColor tells if s is a color of the form #rrggbb
+func IsColor(s string) bool {
+	if len(s) != 7 || s[0] != '#' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}